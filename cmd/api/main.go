@@ -1,7 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/scalarorg/scalar-service/cmd/api/server"
 	"github.com/scalarorg/scalar-service/config"
@@ -11,9 +16,21 @@ func main() {
 	s := server.New()
 	host := "0.0.0.0"
 
-	err := s.Start(fmt.Sprintf("%s:%s", host, config.Env.PORT))
-	defer s.Close()
-	if err != nil {
-		panic(err)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Start(fmt.Sprintf("%s:%s", host, config.Env.PORT))
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
+
+	select {
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.Shutdown()
+			panic(err)
+		}
+	case <-quit:
+		s.Shutdown()
 	}
 }
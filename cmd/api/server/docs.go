@@ -0,0 +1,103 @@
+package server
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/internal/admin"
+	"github.com/scalarorg/scalar-service/internal/graphql"
+	"github.com/scalarorg/scalar-service/internal/health"
+	"github.com/scalarorg/scalar-service/internal/metrics"
+	"github.com/scalarorg/scalar-service/internal/stats"
+	"github.com/scalarorg/scalar-service/internal/x"
+	"github.com/scalarorg/scalar-service/pkg/openapi"
+	"github.com/scalarorg/scalar-service/pkg/telemetry"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// swaggerUI holds the assets of Swagger UI 5.18.2 (swagger-ui-dist, Apache
+// License 2.0). They are served by the API rather than a CDN, so the docs never
+// run third party code that wasn't reviewed.
+//
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+// SRI hashes of the Swagger UI assets, make swagger-ui-sri prints them when
+// the assets are updated
+const (
+	swaggerUICSSIntegrity = "sha384-rcbEi6xgdPk0iWkAQzT2F3FeBJXdG+ydrawGlfHAFIZG7wU6aKbQaRewysYpmrlW"
+	swaggerUIJSIntegrity  = "sha384-NXtFPpN61oWCuN4D42K6Zd5Rt2+uxeIT36R7kpXBuY9tLnZorzrJ4ykpqwJfgjpZ"
+)
+
+// docsPage renders the document with Swagger UI
+var docsPage = fmt.Sprintf(`<!doctype html>
+<html>
+<head>
+	<meta charset="utf-8">
+	<title>Scalar API</title>
+	<link rel="stylesheet" href="/docs/assets/swagger-ui.css" integrity="%[1]s">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="/docs/assets/swagger-ui-bundle.js" integrity="%[2]s"></script>
+	<script>
+		SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" })
+	</script>
+</body>
+</html>`, swaggerUICSSIntegrity, swaggerUIJSIntegrity)
+
+// setupDocs serves the OpenAPI document of the routes of RouteRecs at
+// /openapi.json and its docs at /docs, with the Swagger UI assets under
+// /docs/assets. The document is built on the first
+// request, once every route is recorded.
+func setupDocs(e *echo.Echo) {
+	openapi.Register(health.Docs()...)
+	openapi.Register(graphql.Docs()...)
+	openapi.Register(metrics.Docs()...)
+	openapi.Register(x.Docs()...)
+	openapi.Register(stats.Docs()...)
+	openapi.Register(admin.Docs()...)
+
+	var once sync.Once
+	var doc *openapi.Document
+	e.GET("/openapi.json", func(c echo.Context) error {
+		once.Do(func() {
+			doc = openapi.Build(openapi.Info{
+				Title:       config.Env.APP_NAME,
+				Version:     telemetry.Version(),
+				Description: "Cross-chain transactions and statistics of the Scalar network",
+			}, openAPIRoutes())
+		})
+		return c.JSON(http.StatusOK, doc)
+	})
+	e.GET("/docs", func(c echo.Context) error {
+		return c.HTML(http.StatusOK, docsPage)
+	})
+	e.StaticFS("/docs/assets", echo.MustSubFS(swaggerUI, "swagger-ui"))
+}
+
+func openAPIRoutes() []openapi.Route {
+	var routes []openapi.Route
+	for module, infos := range RouteRecs {
+		if module == "_empty" {
+			module = ""
+		}
+		for _, info := range infos {
+			v1 := strings.HasPrefix(info.Path, utils.APIV1Prefix+"/")
+			routes = append(routes, openapi.Route{
+				Method:     info.Method,
+				Path:       info.Path,
+				Name:       info.Name,
+				Tag:        module,
+				Envelope:   v1,
+				Deprecated: !v1 && strings.HasPrefix(info.Path, "/api/"),
+			})
+		}
+	}
+	return routes
+}
@@ -2,14 +2,26 @@ package server
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/admin"
+	"github.com/scalarorg/scalar-service/internal/graphql"
 	"github.com/scalarorg/scalar-service/internal/health"
+	"github.com/scalarorg/scalar-service/internal/metrics"
 	"github.com/scalarorg/scalar-service/internal/stats"
 	"github.com/scalarorg/scalar-service/internal/x"
+	"github.com/scalarorg/scalar-service/pkg/utils"
 )
 
 func setupRoute(e *echo.Echo) {
-	api := e.Group("/api")
 	health.Route(e, "/health")
-	x.Route(api, "/x")
-	stats.Route(api, "/stats")
+	graphql.Route(e, "/graphql")
+	metrics.Route(e, "/metrics")
+
+	// The legacy routes under /api are frozen, see middleware.Deprecated
+	api := e.Group("/api")
+	v1 := e.Group(utils.APIV1Prefix)
+	for _, g := range []*echo.Group{api, v1} {
+		x.Route(g, "/x")
+		stats.Route(g, "/stats")
+		admin.Route(g, "/admin")
+	}
 }
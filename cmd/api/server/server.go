@@ -2,18 +2,27 @@ package server
 
 import (
 	"context"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/internal/health"
+	"github.com/scalarorg/scalar-service/pkg/apikey"
+	"github.com/scalarorg/scalar-service/pkg/cache"
 	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/metrics"
 	"github.com/scalarorg/scalar-service/pkg/openobserve"
-	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"github.com/scalarorg/scalar-service/pkg/price"
+	"github.com/scalarorg/scalar-service/pkg/ratelimit"
+	"github.com/scalarorg/scalar-service/pkg/telemetry"
 )
 
+const flushTimeout = 5 * time.Second
+
 type Server struct {
-	Raw           *echo.Echo
-	traceProvider *sdktrace.TracerProvider
+	Raw       *echo.Echo
+	telemetry *telemetry.Telemetry
 }
 
 func New() *Server {
@@ -30,19 +39,40 @@ func New() *Server {
 		Env:         config.Env.ENV,
 	})
 
-	config.InitLogger()
+	t, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:   appName,
+		Env:           config.Env.ENV,
+		TraceExporter: config.Env.TRACE_EXPORTER,
+		OTLPEndpoint:  config.Env.OTLP_ENDPOINT,
+		OTLPHeaders:   config.Env.OTLP_HEADERS,
+		SampleRatio:   config.Env.TRACE_SAMPLE_RATIO,
+		LogSinks:      config.Env.LOG_SINKS,
+		LogFile: telemetry.LogFileConfig{
+			Path:       config.Env.LOG_FILE,
+			MaxSizeMB:  config.Env.LOG_FILE_MAX_SIZE,
+			MaxBackups: config.Env.LOG_FILE_MAX_BACKUPS,
+			MaxAgeDays: config.Env.LOG_FILE_MAX_AGE,
+		},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to set up telemetry")
+	}
+
+	config.InitLogger(t.LogWriters()...)
+	config.WatchReload()
+	log.Info().Str("version", telemetry.Version()).Str("traces", config.Env.TRACE_EXPORTER).Strs("logs", config.Env.LOG_SINKS).Msg("Telemetry initialized")
 
 	e := echo.New()
 	e.HideBanner = true
-	tp := openobserve.SetupTraceHTTP()
 
 	setupAddHandlerEvent(e)
 	setupMiddleware(e)
 	setupErrorHandler(e)
 	setupRoute(e)
+	setupDocs(e)
 	setupValidator(e)
 
-	return &Server{e, tp}
+	return &Server{e, t}
 }
 
 func (s *Server) Start(addr string) error {
@@ -51,19 +81,51 @@ func (s *Server) Start(addr string) error {
 	return s.Raw.Start(addr)
 }
 
-func (s *Server) Close() {
+// Shutdown stops the server gracefully: the readiness probe fails first so the
+// server is taken out of rotation, then in-flight requests drain, logs and
+// traces are flushed and the databases are closed last
+func (s *Server) Shutdown() {
+	health.SetShuttingDown()
+	log.Info().Int("delay", config.Env.SHUTDOWN_DELAY).Msg("Shutting down, readiness probe failing")
+	time.Sleep(time.Duration(config.Env.SHUTDOWN_DELAY) * time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Env.SHUTDOWN_GRACE_PERIOD)*time.Second)
+	defer cancel()
+
+	if err := s.Raw.Shutdown(ctx); err != nil {
+		log.Err(err).Msg("Error draining in-flight requests")
+		s.Raw.Close()
+	}
 	closeSvcs()
-	s.Raw.Close()
-	err := s.traceProvider.Shutdown(context.Background())
-	if err != nil {
-		log.Err(err).Msg("Error shutting down trace provider")
+
+	// Flushing gets its own deadline so a slow drain doesn't drop the logs
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancelFlush()
+
+	log.Info().Msg("Server stopped")
+	if err := s.telemetry.Shutdown(flushCtx); err != nil {
+		log.Err(err).Msg("Error flushing traces and logs")
+	}
+
+	if err := db.Close(); err != nil {
+		log.Err(err).Msg("Error closing databases")
 	}
 }
 
 func loadSvcs() {
 	db.Init()
+	apikey.Init()
+	cache.Init()
+	ratelimit.Init()
+	price.Init()
+	metrics.Init()
 }
 
+// closeSvcs closes the services but the databases, which are closed once the
+// logs are flushed
 func closeSvcs() {
-	db.Close()
+	metrics.Close()
+	apikey.Close()
+	ratelimit.Close()
+	cache.Close()
 }
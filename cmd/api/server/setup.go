@@ -1,6 +1,7 @@
 package server
 
 import (
+	"net"
 	"os"
 	"sort"
 	"strings"
@@ -8,6 +9,10 @@ import (
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+	appmiddleware "github.com/scalarorg/scalar-service/internal/middleware"
+	statshandlers "github.com/scalarorg/scalar-service/internal/stats/handlers"
 	"github.com/scalarorg/scalar-service/pkg/openobserve"
 	"github.com/scalarorg/scalar-service/pkg/utils"
 )
@@ -17,6 +22,8 @@ type RouteInfo struct {
 	Path        string
 	Handler     string
 	Middlewares []string
+	// Name is the full name of the handler
+	Name string
 }
 
 var RouteRecs map[string][]RouteInfo = make(map[string][]RouteInfo)
@@ -60,20 +67,128 @@ func setupAddHandlerEvent(e *echo.Echo) {
 			Path:        route.Path,
 			Handler:     h,
 			Middlewares: middlewareNames,
+			Name:        routeName,
 		})
 	}
 }
 
 func setupMiddleware(e *echo.Echo) {
-	//log.Info().Any("CORS", config.Env.CORS_WHITE_LIST).Msg("setupMiddleware")
+	e.IPExtractor = ipExtractor()
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
-	}))
+	setupCORS(e)
 	e.Use(openobserve.Middleware())
+	e.Use(appmiddleware.Deprecated())
 	e.Use(utils.RequestLogMiddleware())
+	e.Use(appmiddleware.APIKeyAuth())
+	e.Use(appmiddleware.RateLimit())
+	e.Use(appmiddleware.HTTPCache())
+}
+
+// ipExtractor reads the client IP from X-Forwarded-For when the request comes
+// from one of TRUSTED_PROXIES, from the connection otherwise
+func ipExtractor() echo.IPExtractor {
+	if len(config.Env.TRUSTED_PROXIES) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	opts := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range config.Env.TRUSTED_PROXIES {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Fatal().Err(err).Str("cidr", cidr).Msg("Invalid trusted proxy")
+		}
+		opts = append(opts, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...)
+}
+
+// corsGroups are the route groups locked down to their own whitelist, every
+// other route allows the origins of CORS_WHITE_LIST. The whitelists are
+// reloadable.
+var corsGroups = []struct {
+	prefix    string
+	whiteList func(env *config.ReloadableEnv) []string
+}{
+	{"/api/admin", func(env *config.ReloadableEnv) []string { return env.CORS_ADMIN_WHITE_LIST }},
+}
+
+// corsExposeHeaders are the response headers browsers may read
+var corsExposeHeaders = []string{
+	appmiddleware.HeaderRateLimitLimit,
+	appmiddleware.HeaderRateLimitRemaining,
+	appmiddleware.HeaderRateLimitReset,
+	echo.HeaderRetryAfter,
+	statshandlers.HeaderNextCursor,
+	appmiddleware.HeaderDeprecation,
+	appmiddleware.HeaderSunset,
+	appmiddleware.HeaderLink,
+	appmiddleware.HeaderETag,
+}
+
+// setupCORS adds a CORS middleware for each of corsGroups and one for the
+// other routes, each skipping the requests of the others
+func setupCORS(e *echo.Echo) {
+	corsConfig := func(skipper middleware.Skipper, whiteList func(env *config.ReloadableEnv) []string) middleware.CORSConfig {
+		return middleware.CORSConfig{
+			Skipper: skipper,
+			AllowOriginFunc: func(origin string) (bool, error) {
+				return allowOrigin(whiteList(config.Reloadable()), origin), nil
+			},
+			AllowMethods:  []string{echo.GET, echo.HEAD, echo.PUT, echo.PATCH, echo.POST, echo.DELETE},
+			AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, appmiddleware.HeaderAPIKey, appmiddleware.HeaderIfNoneMatch},
+			ExposeHeaders: corsExposeHeaders,
+			MaxAge:        config.Env.CORS_MAX_AGE,
+		}
+	}
+
+	for _, group := range corsGroups {
+		prefix := group.prefix
+		e.Use(middleware.CORSWithConfig(corsConfig(func(c echo.Context) bool {
+			return !inGroup(c.Request().URL.Path, prefix)
+		}, group.whiteList)))
+	}
+	e.Use(middleware.CORSWithConfig(corsConfig(func(c echo.Context) bool {
+		for _, group := range corsGroups {
+			if inGroup(c.Request().URL.Path, group.prefix) {
+				return true
+			}
+		}
+		return false
+	}, func(env *config.ReloadableEnv) []string { return env.CORS_WHITE_LIST })))
+}
+
+// inGroup matches the routes of both API versions against the legacy prefix
+func inGroup(path, prefix string) bool {
+	path = utils.UnversionedPath(path)
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// allowOrigin checks the origin against a whitelist. A * entry allows every
+// origin and https://*.scalar.org allows the subdomains of scalar.org over
+// https.
+func allowOrigin(whiteList []string, origin string) bool {
+	for _, allowed := range whiteList {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		scheme, domain, ok := strings.Cut(allowed, "*.")
+		if !ok {
+			continue
+		}
+		subdomain, found := strings.CutPrefix(origin, scheme)
+		if !found {
+			continue
+		}
+		subdomain, found = strings.CutSuffix(subdomain, "."+domain)
+		if found && subdomain != "" && !strings.ContainsAny(subdomain, "/:@") {
+			return true
+		}
+	}
+	return false
 }
 
 func setupErrorHandler(e *echo.Echo) {
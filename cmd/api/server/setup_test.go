@@ -0,0 +1,38 @@
+package server
+
+import "testing"
+
+func TestAllowOrigin(t *testing.T) {
+	whiteList := []string{"https://app.scalar.org", "https://*.scalar.org"}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.scalar.org", true},
+		{"https://staging.scalar.org", true},
+		{"https://a.b.scalar.org", true},
+		{"https://scalar.org", false},
+		{"https://.scalar.org", false},
+		{"https://evilscalar.org", false},
+		{"https://evil.com/.scalar.org", false},
+		{"https://evil.com:443/.scalar.org", false},
+		{"https://evil.com@x.scalar.org", false},
+		{"https://x.scalar.org.evil.com", false},
+		{"https://x.scalar.org:8443", false},
+		{"http://x.scalar.org", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := allowOrigin(whiteList, tt.origin); got != tt.want {
+			t.Errorf("allowOrigin(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+
+	if !allowOrigin([]string{"*"}, "https://evil.com") {
+		t.Error("allowOrigin() rejected an origin with the * white list")
+	}
+	if allowOrigin(nil, "https://app.scalar.org") {
+		t.Error("allowOrigin() allowed an origin with an empty white list")
+	}
+}
@@ -1,8 +1,114 @@
 package api_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/middleware"
+	"github.com/scalarorg/scalar-service/pkg/apikey"
+	"github.com/scalarorg/scalar-service/pkg/db"
 )
 
+type testStore map[string]*db.APIKey
+
+func (s testStore) GetAPIKeyByHash(_ context.Context, hash string) (*db.APIKey, error) {
+	if key, ok := s[hash]; ok {
+		return key, nil
+	}
+	return nil, db.ErrAPIKeyNotFound
+}
+
 func TestAuth(t *testing.T) {
+	e := echo.New()
+	e.Use(middleware.APIKeyAuth())
+	e.GET("/public", func(c echo.Context) error {
+		return c.String(http.StatusOK, "anonymous="+strconv.FormatBool(middleware.APIKeyFromContext(c) == nil))
+	})
+	e.GET("/export", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, middleware.RequireScope(apikey.ScopeExport))
+
+	past := time.Now().Add(-time.Hour)
+	store := testStore{}
+	secrets := make(map[string]string)
+	for name, key := range map[string]*db.APIKey{
+		"export":  {ID: 1, Scopes: []string{apikey.ScopeRead, apikey.ScopeExport}},
+		"read":    {ID: 2, Scopes: []string{apikey.ScopeRead}},
+		"revoked": {ID: 3, Scopes: []string{apikey.ScopeExport}, RevokedAt: &past},
+		"expired": {ID: 4, Scopes: []string{apikey.ScopeExport}, ExpiresAt: &past},
+	} {
+		secret, _, err := apikey.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		secrets[name] = secret
+		store[apikey.Hash(secret)] = key
+	}
+
+	tests := []struct {
+		name     string
+		disabled bool
+		path     string
+		key      string
+		status   int
+		body     string
+	}{
+		{name: "anonymous", path: "/public", status: http.StatusOK, body: "anonymous=true"},
+		{name: "valid key", path: "/public", key: secrets["read"], status: http.StatusOK, body: "anonymous=false"},
+		{name: "malformed key", path: "/public", key: "not-a-key", status: http.StatusUnauthorized},
+		{name: "revoked key", path: "/public", key: secrets["revoked"], status: http.StatusUnauthorized},
+		{name: "expired key", path: "/public", key: secrets["expired"], status: http.StatusUnauthorized},
+		{name: "scope without key", path: "/export", status: http.StatusUnauthorized},
+		{name: "key with the scope", path: "/export", key: secrets["export"], status: http.StatusOK},
+		{name: "key out of scope", path: "/export", key: secrets["read"], status: http.StatusForbidden},
+		{name: "revoked key with the scope", path: "/export", key: secrets["revoked"], status: http.StatusUnauthorized},
+		{name: "disabled keys", disabled: true, path: "/public", key: secrets["export"], status: http.StatusOK, body: "anonymous=true"},
+		{name: "scope with disabled keys", disabled: true, path: "/export", key: secrets["export"], status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apikey.Default = store
+			if tt.disabled {
+				apikey.Default = nil
+			}
+			defer func() { apikey.Default = nil }()
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set(middleware.HeaderAPIKey, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, err := apikey.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, prefix) || !strings.HasPrefix(prefix, "sk_") {
+		t.Errorf("key %q doesn't start with prefix %q", key, prefix)
+	}
+	if apikey.Hash(key) != apikey.Hash(key) || apikey.Hash(key) == key {
+		t.Error("hash is not stable or is the key itself")
+	}
+
+	other, _, _ := apikey.Generate()
+	if other == key {
+		t.Error("generated the same key twice")
+	}
 }
@@ -1,17 +1,21 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 )
 
 type ServerEnv struct {
-	ENV             string
-	CORS_WHITE_LIST []string
+	ENV string
 
 	APP_NAME string `validate:"min=1"`
 
@@ -25,80 +29,386 @@ type ServerEnv struct {
 	RELAYER_DB_URI string `validate:"uri"`
 	INDEXER_DB_URI string `validate:"uri"`
 
-	OPENOBSERVE_ENDPOINT   string `validate:"url"`
-	OPENOBSERVE_CREDENTIAL string `validate:"min=1"`
+	// Required when OpenObserve is a trace exporter or a log sink
+	OPENOBSERVE_ENDPOINT   string `validate:"omitempty,url"`
+	OPENOBSERVE_CREDENTIAL string
+
+	TRACE_EXPORTER string `validate:"oneof=none stdout otlp-http otlp-grpc openobserve"`
+	// Collector URL of the otlp exporters, http:// disables TLS
+	OTLP_ENDPOINT string `validate:"omitempty,url"`
+	// Headers sent to the collector, as key=value,key2=value2
+	OTLP_HEADERS map[string]string
+	// Share of the traces started here that are sampled, from 0 to 1
+	TRACE_SAMPLE_RATIO float64 `validate:"min=0,max=1"`
+
+	LOG_SINKS []string `validate:"min=1,dive,oneof=console file openobserve"`
+	LOG_FILE  string
+	// Megabytes the log file may reach before it is rotated, then the number
+	// and days of rotated files kept, 0 keeping them all
+	LOG_FILE_MAX_SIZE    int `validate:"min=1"`
+	LOG_FILE_MAX_BACKUPS int `validate:"min=0"`
+	LOG_FILE_MAX_AGE     int `validate:"min=0"`
 
 	BITCOIN_CHAIN_ID string `validate:"min=4"`
+
+	CACHE_DRIVER string `validate:"oneof=memory redis"`
+	CACHE_SIZE   int    `validate:"min=1"`
+	REDIS_URL    string `validate:"required_if=CACHE_DRIVER redis,required_if=RATE_LIMIT_DRIVER redis"`
+
+	RATE_LIMIT_DRIVER string `validate:"oneof=memory redis"`
+	// CIDRs of the proxies whose X-Forwarded-For is trusted for the client IP
+	TRUSTED_PROXIES []string `validate:"dive,cidr"`
+
+	ADMIN_TOKEN string
+
+	PRICE_PROVIDER   string `validate:"oneof=none file http"`
+	PRICE_FILE       string `validate:"required_if=PRICE_PROVIDER file"`
+	PRICE_ORACLE_URL string `validate:"required_if=PRICE_PROVIDER http"`
+
+	// Seconds between two collections of the Prometheus metrics
+	METRICS_INTERVAL int `validate:"min=5"`
+	// Seconds back the pending transfers and redeems are looked for, which
+	// bounds the cost of their collection
+	METRICS_PENDING_WINDOW int `validate:"min=3600"`
+
+	// Seconds the latest indexed block may lag before /health is degraded
+	HEALTH_MAX_INDEXER_LAG int `validate:"min=1"`
+
+	// Seconds the server keeps serving once the readiness probe fails, then
+	// the seconds in-flight requests are given to complete. With the 5 seconds
+	// the logs are flushed in, they must fit in the terminationGracePeriodSeconds
+	// of the pod, 30 by default, or the server is killed before it is done.
+	SHUTDOWN_DELAY        int `validate:"min=0"`
+	SHUTDOWN_GRACE_PERIOD int `validate:"min=1"`
+
+	// Dates, as 2006-01-02, the legacy API routes were deprecated on and will
+	// be removed on
+	LEGACY_API_DEPRECATION string `validate:"datetime=2006-01-02"`
+	LEGACY_API_SUNSET      string `validate:"omitempty,datetime=2006-01-02"`
+
+	// Seconds browsers may cache the answers of CORS preflight requests
+	CORS_MAX_AGE int `validate:"min=0"`
+
+	// Bytes from which responses are compressed
+	COMPRESS_MIN_LENGTH int `validate:"min=0"`
+
+	// Cost a GraphQL query may reach, every field costs one times the size of
+	// the lists it is nested in
+	GRAPHQL_MAX_COST int `validate:"min=1"`
+}
+
+// ReloadableEnv holds the settings applied again on SIGHUP, read them through
+// Reloadable as they may change at any time
+type ReloadableEnv struct {
+	LOG_LEVEL string `validate:"oneof=trace debug info warn error"`
+	// Origins allowed by the public routes and by the admin API, which allows
+	// none by default. Entries may be * or a wildcard subdomain such as
+	// https://*.scalar.org.
+	CORS_WHITE_LIST       []string `validate:"min=1"`
+	CORS_ADMIN_WHITE_LIST []string
+
+	// Seconds the responses of each kind of stats endpoint are fresh
+	CACHE_SUMMARY_TTL     int `validate:"min=1"`
+	CACHE_CHART_TTL       int `validate:"min=1"`
+	CACHE_LEADERBOARD_TTL int `validate:"min=1"`
+	CACHE_PROFILE_TTL     int `validate:"min=1"`
+
+	// Seconds browsers may reuse the responses of the public routes, shared
+	// caches such as CDNs may keep the stats for their TTL above and the
+	// other responses for HTTP_CACHE_S_MAXAGE. Executed transactions never
+	// change and are kept for HTTP_CACHE_EXECUTED_MAX_AGE.
+	HTTP_CACHE_MAX_AGE          int `validate:"min=0"`
+	HTTP_CACHE_S_MAXAGE         int `validate:"min=0"`
+	HTTP_CACHE_EXECUTED_MAX_AGE int `validate:"min=0"`
+
+	// Tokens refilled every minute in the bucket of each client, 0 disabling
+	// rate limiting, and the tokens the bucket holds at most
+	RATE_LIMIT_PER_MINUTE int `validate:"min=0"`
+	RATE_LIMIT_BURST      int `validate:"min=1"`
 }
 
 var Env ServerEnv
 
+var reloadable atomic.Pointer[ReloadableEnv]
+
+// Reloadable returns the current reloadable settings
+func Reloadable() *ReloadableEnv {
+	return reloadable.Load()
+}
+
+// envFile is the dotenv file of the last load, read again on reload
+var envFile string
+
 func LoadEnvWithPath(path string) {
-	err := godotenv.Load(os.ExpandEnv(path))
-	if err != nil {
+	if _, err := os.Stat(os.ExpandEnv(path)); err != nil {
 		log.Fatalf("Error loading %s file: %s", path, err)
 	}
+	envFile = path
 
 	loadEnv()
 }
 
+// LoadEnv loads the settings from, by precedence: the environment, the .env
+// file of the environment if it exists, the YAML or TOML file of CONFIG_FILE
+// and the defaults. See lookup for the _FILE secrets.
 func LoadEnv() {
 	if os.Getenv("ENV") == "" {
 		os.Setenv("ENV", "development")
-		err := godotenv.Load(os.ExpandEnv(".env"))
-		if err != nil {
-			log.Fatalln("Error loading .env file: ", err)
-		}
+		envFile = ".env"
 	} else if os.Getenv("ENV") == "test" {
-		err := godotenv.Load(os.ExpandEnv(".env.test"))
-		if err != nil {
-			log.Fatalln("Error loading .env.test file: ", err)
-		}
+		envFile = ".env.test"
 	}
 
 	loadEnv()
 }
 
 func loadEnv() {
-	rawCORSWhiteList := os.Getenv("CORS_WHITE_LIST")
-	var corsWhiteList []string
-	if rawCORSWhiteList == "" {
-		corsWhiteList = []string{
-			"http://localhost:3000",
+	env, reloadableEnv, err := readEnv()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	Env = *env
+	reloadable.Store(reloadableEnv)
+}
+
+// readEnv reads and validates every setting
+func readEnv() (*ServerEnv, *ReloadableEnv, error) {
+	layers, err := readLayers()
+	if err != nil {
+		return nil, nil, err
+	}
+	get := func(key string) string {
+		value, lookupErr := lookup(layers, key)
+		if lookupErr != nil {
+			err = errors.Join(err, lookupErr)
+		}
+		return value
+	}
+	// getInt falls back to def when key is unset, a malformed value is an
+	// error and the range is checked by the validate tags
+	getInt := func(key string, def int) int {
+		raw := get(key)
+		if raw == "" {
+			return def
+		}
+		n, convErr := strconv.Atoi(raw)
+		if convErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid config %s: %q is not an integer", key, raw))
+			return def
+		}
+		return n
+	}
+	getFloat := func(key string, def float64) float64 {
+		raw := get(key)
+		if raw == "" {
+			return def
+		}
+		f, convErr := strconv.ParseFloat(raw, 64)
+		if convErr != nil {
+			err = errors.Join(err, fmt.Errorf("invalid config %s: %q is not a number", key, raw))
+			return def
+		}
+		return f
+	}
+	// getList splits a comma separated value, its entries are trimmed and the
+	// empty ones dropped
+	getList := func(key string, def []string) []string {
+		var list []string
+		for _, entry := range strings.Split(get(key), ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				list = append(list, entry)
+			}
+		}
+		if len(list) == 0 {
+			return def
+		}
+		return list
+	}
+
+	corsWhiteList := getList("CORS_WHITE_LIST", []string{"*"})
+	trustedProxies := getList("TRUSTED_PROXIES", nil)
+	corsAdminWhiteList := getList("CORS_ADMIN_WHITE_LIST", nil)
+	logSinks := getList("LOG_SINKS", []string{"console", "openobserve"})
+
+	otlpHeaders := make(map[string]string)
+	if rawOTLPHeaders := get("OTLP_HEADERS"); rawOTLPHeaders != "" {
+		for _, header := range strings.Split(rawOTLPHeaders, ",") {
+			key, value, ok := strings.Cut(header, "=")
+			if !ok {
+				err = errors.Join(err, errors.New("invalid config OTLP_HEADERS: headers must be key=value"))
+				break
+			}
+			otlpHeaders[strings.TrimSpace(key)] = strings.TrimSpace(value)
 		}
-	} else {
-		corsWhiteList = strings.Split(rawCORSWhiteList, ",")
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "12345"
+	env := &ServerEnv{
+		ENV: get("ENV"),
+
+		APP_NAME: get("APP_NAME"),
+		PORT:     withDefault(get("PORT"), "12345"),
+
+		OPENOBSERVE_ENDPOINT:   get("OPENOBSERVE_ENDPOINT"),
+		OPENOBSERVE_CREDENTIAL: get("OPENOBSERVE_CREDENTIAL"),
+
+		TRACE_EXPORTER:     withDefault(get("TRACE_EXPORTER"), "openobserve"),
+		OTLP_ENDPOINT:      get("OTLP_ENDPOINT"),
+		OTLP_HEADERS:       otlpHeaders,
+		TRACE_SAMPLE_RATIO: getFloat("TRACE_SAMPLE_RATIO", 1),
+
+		LOG_SINKS:            logSinks,
+		LOG_FILE:             get("LOG_FILE"),
+		LOG_FILE_MAX_SIZE:    getInt("LOG_FILE_MAX_SIZE", 100),
+		LOG_FILE_MAX_BACKUPS: getInt("LOG_FILE_MAX_BACKUPS", 5),
+		LOG_FILE_MAX_AGE:     getInt("LOG_FILE_MAX_AGE", 30),
+
+		RELAYER_DB_URI:   get("RELAYER_DB_URI"),
+		INDEXER_DB_URI:   get("INDEXER_DB_URI"),
+		BITCOIN_CHAIN_ID: get("BITCOIN_CHAIN_ID"),
+
+		CACHE_DRIVER: withDefault(get("CACHE_DRIVER"), "memory"),
+		CACHE_SIZE:   getInt("CACHE_SIZE", 1024),
+		REDIS_URL:    get("REDIS_URL"),
+
+		RATE_LIMIT_DRIVER: withDefault(get("RATE_LIMIT_DRIVER"), "memory"),
+		TRUSTED_PROXIES:   trustedProxies,
+
+		ADMIN_TOKEN: get("ADMIN_TOKEN"),
+
+		PRICE_PROVIDER:   withDefault(get("PRICE_PROVIDER"), "none"),
+		PRICE_FILE:       get("PRICE_FILE"),
+		PRICE_ORACLE_URL: get("PRICE_ORACLE_URL"),
+
+		METRICS_INTERVAL:       getInt("METRICS_INTERVAL", 30),
+		METRICS_PENDING_WINDOW: getInt("METRICS_PENDING_WINDOW", 7*24*3600),
+
+		HEALTH_MAX_INDEXER_LAG: getInt("HEALTH_MAX_INDEXER_LAG", 600),
+
+		SHUTDOWN_DELAY:        getInt("SHUTDOWN_DELAY", 5),
+		SHUTDOWN_GRACE_PERIOD: getInt("SHUTDOWN_GRACE_PERIOD", 15),
+
+		LEGACY_API_DEPRECATION: withDefault(get("LEGACY_API_DEPRECATION"), "2026-10-19"),
+		LEGACY_API_SUNSET:      get("LEGACY_API_SUNSET"),
+
+		CORS_MAX_AGE: getInt("CORS_MAX_AGE", 600),
+
+		COMPRESS_MIN_LENGTH: getInt("COMPRESS_MIN_LENGTH", 1024),
+
+		GRAPHQL_MAX_COST: getInt("GRAPHQL_MAX_COST", 10000),
 	}
 
-	Env = ServerEnv{
-		ENV:             os.Getenv("ENV"),
-		CORS_WHITE_LIST: corsWhiteList,
+	reloadableEnv := &ReloadableEnv{
+		LOG_LEVEL: withDefault(strings.ToLower(get("LOG_LEVEL")), "debug"),
+
+		CORS_WHITE_LIST:       corsWhiteList,
+		CORS_ADMIN_WHITE_LIST: corsAdminWhiteList,
 
-		APP_NAME: os.Getenv("APP_NAME"),
-		PORT:     port,
+		CACHE_SUMMARY_TTL:     getInt("CACHE_SUMMARY_TTL", 30),
+		CACHE_CHART_TTL:       getInt("CACHE_CHART_TTL", 60),
+		CACHE_LEADERBOARD_TTL: getInt("CACHE_LEADERBOARD_TTL", 300),
+		CACHE_PROFILE_TTL:     getInt("CACHE_PROFILE_TTL", 30),
 
-		OPENOBSERVE_ENDPOINT:   os.Getenv("OPENOBSERVE_ENDPOINT"),
-		OPENOBSERVE_CREDENTIAL: os.Getenv("OPENOBSERVE_CREDENTIAL"),
+		HTTP_CACHE_MAX_AGE:          getInt("HTTP_CACHE_MAX_AGE", 5),
+		HTTP_CACHE_S_MAXAGE:         getInt("HTTP_CACHE_S_MAXAGE", 15),
+		HTTP_CACHE_EXECUTED_MAX_AGE: getInt("HTTP_CACHE_EXECUTED_MAX_AGE", 86400),
 
-		RELAYER_DB_URI:   os.Getenv("RELAYER_DB_URI"),
-		INDEXER_DB_URI:   os.Getenv("INDEXER_DB_URI"),
-		BITCOIN_CHAIN_ID: os.Getenv("BITCOIN_CHAIN_ID"),
+		RATE_LIMIT_PER_MINUTE: getInt("RATE_LIMIT_PER_MINUTE", 120),
+		RATE_LIMIT_BURST:      getInt("RATE_LIMIT_BURST", 60),
+	}
+	if err != nil {
+		return nil, nil, err
 	}
 
 	validate := validator.New()
-	err := validate.Struct(Env)
+	if err := validate.Struct(env); err != nil {
+		return nil, nil, validationError(err)
+	}
+	if err := validate.Struct(reloadableEnv); err != nil {
+		return nil, nil, validationError(err)
+	}
+	if err := validateTelemetry(env); err != nil {
+		return nil, nil, err
+	}
 
-	if err != nil {
-		panic(err)
+	env.IsProd = env.ENV == "production"
+	env.IsStaging = env.ENV == "staging"
+	env.IsDev = env.ENV == "development" || len(env.ENV) == 0
+	env.IsTest = env.ENV == "test"
+	return env, reloadableEnv, nil
+}
+
+// UsesOpenObserve reports whether OpenObserve is a trace exporter or a log sink
+func (e *ServerEnv) UsesOpenObserve() bool {
+	return e.TRACE_EXPORTER == "openobserve" || slices.Contains(e.LOG_SINKS, "openobserve")
+}
+
+// validateTelemetry checks the settings the trace exporter and the log sinks
+// depend on
+func validateTelemetry(env *ServerEnv) error {
+	var missing []string
+	if env.UsesOpenObserve() {
+		if env.OPENOBSERVE_ENDPOINT == "" {
+			missing = append(missing, "OPENOBSERVE_ENDPOINT")
+		}
+		if env.OPENOBSERVE_CREDENTIAL == "" {
+			missing = append(missing, "OPENOBSERVE_CREDENTIAL")
+		}
 	}
+	if (env.TRACE_EXPORTER == "otlp-http" || env.TRACE_EXPORTER == "otlp-grpc") && env.OTLP_ENDPOINT == "" {
+		missing = append(missing, "OTLP_ENDPOINT")
+	}
+	if slices.Contains(env.LOG_SINKS, "file") && env.LOG_FILE == "" {
+		missing = append(missing, "LOG_FILE")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("invalid config: %s required by TRACE_EXPORTER=%s and LOG_SINKS=%s",
+			strings.Join(missing, ", "), env.TRACE_EXPORTER, strings.Join(env.LOG_SINKS, ","))
+	}
+	return nil
+}
 
-	Env.IsProd = Env.ENV == "production"
-	Env.IsStaging = Env.ENV == "staging"
-	Env.IsDev = Env.ENV == "development" || len(Env.ENV) == 0
-	Env.IsTest = Env.ENV == "test"
+// readLayers reads the dotenv file, then the config file, the first layer
+// having precedence
+func readLayers() ([]map[string]string, error) {
+	layers := make([]map[string]string, 0, 2)
+	if envFile != "" {
+		values, err := godotenv.Read(os.ExpandEnv(envFile))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error loading %s file: %w", envFile, err)
+		}
+		layers = append(layers, values)
+	}
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, values)
+	}
+	return layers, nil
+}
+
+// validationError names the settings that failed validation, without their
+// values as they may be secrets
+func validationError(err error) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		rule := e.Tag()
+		if e.Param() != "" {
+			rule += "=" + e.Param()
+		}
+		msgs[i] = fmt.Sprintf("%s must satisfy %s", e.Field(), rule)
+	}
+	return fmt.Errorf("invalid config: %s", strings.Join(msgs, ", "))
+}
+
+func withDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
 }
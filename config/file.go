@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// secretSuffix marks a setting whose value is read from the file it names,
+// e.g. RELAYER_DB_URI_FILE=/run/secrets/relayer_db_uri
+const secretSuffix = "_FILE"
+
+// readConfigFile reads a YAML or TOML config file into settings. Nested keys
+// are joined with an underscore and upper cased, so cache.driver sets
+// CACHE_DRIVER, and lists are joined with commas.
+func readConfigFile(path string) (map[string]string, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config file: %w", err)
+	}
+
+	tree := make(map[string]any)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, &tree)
+	case ".toml":
+		err = toml.Unmarshal(raw, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", tree, values)
+	return values, nil
+}
+
+func flatten(prefix string, tree map[string]any, values map[string]string) {
+	for key, value := range tree {
+		key = strings.ToUpper(prefix + key)
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key+"_", v, values)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// lookup returns the first value of key in the environment then in layers.
+// In each of them key_FILE has precedence over key and names a file holding
+// the value, so secrets can be mounted rather than passed in plain text.
+func lookup(layers []map[string]string, key string) (string, error) {
+	sources := make([]func(string) (string, bool), 0, len(layers)+1)
+	sources = append(sources, os.LookupEnv)
+	for _, layer := range layers {
+		sources = append(sources, func(key string) (string, bool) {
+			value, ok := layer[key]
+			return value, ok
+		})
+	}
+
+	for _, source := range sources {
+		if path, ok := source(key + secretSuffix); ok && path != "" {
+			secret, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("invalid config %s%s: %w", key, secretSuffix, err)
+			}
+			return strings.TrimRight(string(secret), "\r\n"), nil
+		}
+		if value, ok := source(key); ok {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// changedKeys lists the settings that differ between two configs
+func changedKeys(a, b map[string]string) []string {
+	keys := make([]string, 0)
+	for key, value := range a {
+		if b[key] != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
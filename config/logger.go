@@ -6,29 +6,19 @@ import (
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
-	"github.com/scalarorg/scalar-service/pkg/openobserve"
 )
 
-func InitLogger() {
-	if Env.IsTest {
-		zerolog.SetGlobalLevel(zerolog.Disabled)
-	} else {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	}
+// InitLogger writes the logs to every writer of the log sinks, stdout when
+// there is none
+func InitLogger(writers ...io.Writer) {
+	setLogLevel(Reloadable())
+	OnReload(setLogLevel)
 
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
 
 	var writer io.Writer
-	var subWriters []io.Writer = []io.Writer{}
-
-	o2Writer := openobserve.NewLogWriter(zerolog.InfoLevel)
-	consoleWriter := zerolog.ConsoleWriter{Out: os.Stderr}
-
-	subWriters = append(subWriters, consoleWriter)
-	subWriters = append(subWriters, o2Writer)
-
-	if len(subWriters) >= 1 {
-		writer = zerolog.MultiLevelWriter(subWriters...)
+	if len(writers) >= 1 {
+		writer = zerolog.MultiLevelWriter(writers...)
 	} else {
 		writer = os.Stdout
 	}
@@ -36,3 +26,15 @@ func InitLogger() {
 	log.Logger = log.Output(writer)
 	log.Info().Msg("Logger initialized")
 }
+
+func setLogLevel(env *ReloadableEnv) {
+	if Env.IsTest {
+		zerolog.SetGlobalLevel(zerolog.Disabled)
+		return
+	}
+	level, err := zerolog.ParseLevel(env.LOG_LEVEL)
+	if err != nil {
+		level = zerolog.DebugLevel
+	}
+	zerolog.SetGlobalLevel(level)
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	reloadLock  sync.Mutex
+	reloadHooks []func(*ReloadableEnv)
+)
+
+// OnReload registers fn to apply the reloadable settings after every reload
+func OnReload(fn func(*ReloadableEnv)) {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	reloadHooks = append(reloadHooks, fn)
+}
+
+// Reload reads the settings again and applies the reloadable ones. The other
+// settings need a restart, they keep their value and the changed ones are
+// logged. An invalid config is rejected as a whole.
+func Reload() error {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+
+	env, reloadableEnv, err := readEnv()
+	if err != nil {
+		return err
+	}
+	if changed := changedKeys(settings(Env), settings(*env)); len(changed) > 0 {
+		log.Warn().Strs("keys", changed).Msg("Config changes ignored until restart")
+	}
+
+	reloadable.Store(reloadableEnv)
+	for _, fn := range reloadHooks {
+		fn(reloadableEnv)
+	}
+	return nil
+}
+
+// WatchReload reloads the settings on SIGHUP
+func WatchReload() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		for range ch {
+			if err := Reload(); err != nil {
+				log.Error().Err(err).Msg("Failed to reload config, keeping the current one")
+				continue
+			}
+			log.Info().Msg("Config reloaded")
+		}
+	}()
+}
+
+// settings formats the fields of a settings struct by name
+func settings(env any) map[string]string {
+	v := reflect.ValueOf(env)
+	values := make(map[string]string, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		values[v.Type().Field(i).Name] = fmt.Sprint(v.Field(i).Interface())
+	}
+	return values
+}
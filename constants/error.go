@@ -3,5 +3,6 @@ package constants
 import "fmt"
 
 var (
-	ErrInternal = fmt.Errorf("internal error")
+	ErrInternal      = fmt.Errorf("internal error")
+	ErrPriceDisabled = fmt.Errorf("USD valuation is not enabled")
 )
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/scalarorg/scalar-service/internal/admin/handlers"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/openapi"
+)
+
+func Docs() []openapi.Operation {
+	return []openapi.Operation{
+		{Handler: handlers.PurgeCache, Summary: "Purge cached responses", Params: handlers.PurgeCacheOptions{}, Response: handlers.PurgeCacheResult{}, Security: openapi.SecurityAdmin},
+		{Handler: handlers.IssueAPIKey, Summary: "Issue an API key", Description: "The key is only returned once.", Body: handlers.IssueAPIKeyOptions{}, Response: handlers.IssuedAPIKey{}, Status: http.StatusCreated, Security: openapi.SecurityAdmin},
+		{Handler: handlers.ListAPIKeys, Summary: "List API keys", Response: []db.APIKey{}, Security: openapi.SecurityAdmin},
+		{Handler: handlers.RevokeAPIKey, Summary: "Revoke an API key", Params: handlers.APIKeyOptions{}, Response: db.APIKey{}, Security: openapi.SecurityAdmin},
+		{Handler: handlers.GetAPIKeyUsage, Summary: "Daily usage of an API key", Params: handlers.APIKeyUsageOptions{}, Response: handlers.APIKeyUsage{}, Security: openapi.SecurityAdmin},
+	}
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/pkg/apikey"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+type IssueAPIKeyOptions struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"dive,oneof=read export batch graphql"`
+	Tier   string   `json:"tier" validate:"omitempty,oneof=basic partner unlimited"`
+	// ExpiresAt is optional, keys never expire by default
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// IssuedAPIKey is the only response holding the key itself
+type IssuedAPIKey struct {
+	*db.APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyOptions struct {
+	ID uint64 `param:"id" validate:"required"`
+}
+
+type APIKeyUsageOptions struct {
+	ID   uint64 `param:"id" validate:"required"`
+	Days int    `query:"days" validate:"omitempty,min=1,max=365"`
+}
+
+type APIKeyUsage struct {
+	ID    uint64           `json:"id"`
+	Total uint64           `json:"total"`
+	Days  []db.APIKeyUsage `json:"days"`
+}
+
+// APIKeysEnabled answers 503 on the key routes while the keys are disabled
+func APIKeysEnabled(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !apikey.Enabled() {
+			return echo.NewHTTPError(http.StatusServiceUnavailable, apikey.ErrDisabled.Error())
+		}
+		return next(c)
+	}
+}
+
+// IssueAPIKey issues a key with the read scope and the basic tier by default
+func IssueAPIKey(c echo.Context) error {
+	var opts IssueAPIKeyOptions
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+	if len(opts.Scopes) == 0 {
+		opts.Scopes = []string{apikey.ScopeRead}
+	}
+	if opts.Tier == "" {
+		opts.Tier = apikey.TierBasic
+	}
+	if opts.ExpiresAt != nil && opts.ExpiresAt.Before(time.Now()) {
+		return echo.NewHTTPError(http.StatusBadRequest, "expires_at must be in the future")
+	}
+
+	key, secret, err := apikey.Issue(c.Request().Context(), opts.Name, opts.Scopes, opts.Tier, opts.ExpiresAt)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return utils.JSON(c, http.StatusCreated, IssuedAPIKey{APIKey: key, Key: secret})
+}
+
+func ListAPIKeys(c echo.Context) error {
+	keys, err := db.ListAPIKeys(c.Request().Context())
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return utils.JSON(c, http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes a key, it is kept for its usage history
+func RevokeAPIKey(c echo.Context) error {
+	var opts APIKeyOptions
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+
+	key, err := apikey.Revoke(c.Request().Context(), opts.ID)
+	if errors.Is(err, db.ErrAPIKeyNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return utils.JSON(c, http.StatusOK, key)
+}
+
+// GetAPIKeyUsage returns the daily requests of a key over the last days, 30
+// by default. The requests of the last minute are not counted yet.
+func GetAPIKeyUsage(c echo.Context) error {
+	var opts APIKeyUsageOptions
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+	if opts.Days == 0 {
+		opts.Days = 30
+	}
+
+	days, err := db.GetAPIKeyUsage(c.Request().Context(), opts.ID, opts.Days)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	usage := APIKeyUsage{ID: opts.ID, Days: days}
+	for _, day := range days {
+		usage.Total += day.Requests
+	}
+	return utils.JSON(c, http.StatusOK, usage)
+}
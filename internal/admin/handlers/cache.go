@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/pkg/cache"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+type PurgeCacheOptions struct {
+	Prefix string `query:"prefix"`
+}
+
+type PurgeCacheResult struct {
+	Purged int `json:"purged"`
+}
+
+// PurgeCache drops cached responses whose key starts with prefix, e.g.
+// /api/stats/summary. An empty prefix purges everything.
+func PurgeCache(c echo.Context) error {
+	var opts PurgeCacheOptions
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+
+	purged, err := cache.Default.Purge(c.Request().Context(), opts.Prefix)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return utils.JSON(c, http.StatusOK, PurgeCacheResult{Purged: purged})
+}
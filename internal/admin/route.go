@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/admin/handlers"
+	"github.com/scalarorg/scalar-service/internal/middleware"
+)
+
+func Route(g *echo.Group, path string) {
+	x := g.Group(path, middleware.AdminAuth())
+
+	x.DELETE("/cache", handlers.PurgeCache)
+
+	apiKeys := x.Group("/api-keys", handlers.APIKeysEnabled)
+	apiKeys.POST("", handlers.IssueAPIKey)
+	apiKeys.GET("", handlers.ListAPIKeys)
+	apiKeys.DELETE("/:id", handlers.RevokeAPIKey)
+	apiKeys.GET("/:id/usage", handlers.GetAPIKeyUsage)
+}
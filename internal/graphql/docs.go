@@ -0,0 +1,12 @@
+package graphql
+
+import (
+	"github.com/scalarorg/scalar-service/internal/graphql/handlers"
+	"github.com/scalarorg/scalar-service/pkg/openapi"
+)
+
+func Docs() []openapi.Operation {
+	return []openapi.Operation{
+		{Handler: handlers.Query, Summary: "GraphQL query", Description: "Query params for GET requests, a JSON body for POST ones. Requires an API key with the graphql scope.", Params: handlers.QueryOptions{}, Response: map[string]any{}, Security: openapi.SecurityAPIKey},
+	}
+}
@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+)
+
+// defaultListSize is the size of the lists queried without a size argument,
+// the default page size of the schema
+const defaultListSize = 10
+
+// listSizeArgs are the arguments bounding the size of a list field
+var listSizeArgs = []string{"limit", "size", "first"}
+
+// listFields are the fields returning lists besides the top* leaderboards,
+// their selections are resolved once per item
+var listFields = []string{"transactions", "chains", "addresses"}
+
+var errQuerySyntax = errors.New("invalid query")
+
+// queryCost estimates the work of a query before it runs: every field costs
+// one, times the size of the lists it is nested in. ok is false when the query
+// can't be parsed, its execution reports the syntax error then.
+func queryCost(query, operationName string, variables map[string]any) (cost int, ok bool) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return 0, false
+	}
+	p := &parser{tokens: tokens, fragments: make(map[string]int)}
+
+	// Fragments may be used before their definition, so they are all located
+	// before the operations are measured
+	type operation struct {
+		name string
+		vars int
+		body int
+	}
+	var operations []operation
+	for p.peek() != "" {
+		switch p.peek() {
+		case "fragment":
+			p.pos += 2
+			if !p.accept("on") {
+				return 0, false
+			}
+			p.pos++
+			name := p.tokens[p.pos-3]
+			p.directives()
+			p.fragments[name] = p.pos
+		case "query", "mutation", "subscription":
+			p.pos++
+			op := operation{vars: -1}
+			if p.peek() != "(" && p.peek() != "@" && p.peek() != "{" {
+				op.name = p.next()
+			}
+			if p.peek() == "(" {
+				op.vars = p.pos
+				p.skipBalanced("(", ")")
+			}
+			p.directives()
+			op.body = p.pos
+			operations = append(operations, op)
+		case "{":
+			operations = append(operations, operation{vars: -1, body: p.pos})
+		default:
+			return 0, false
+		}
+		if p.peek() != "{" {
+			return 0, false
+		}
+		p.skipBalanced("{", "}")
+	}
+
+	for _, op := range operations {
+		if operationName != "" && op.name != operationName {
+			continue
+		}
+		p.variables = make(map[string]any, len(variables))
+		for name, value := range variables {
+			p.variables[name] = value
+		}
+		if op.vars >= 0 {
+			p.pos = op.vars
+			if err := p.variableDefaults(); err != nil {
+				return 0, false
+			}
+		}
+		p.pos = op.body
+		c, err := p.selectionSet(1, 0)
+		if err != nil {
+			return 0, false
+		}
+		cost = max(cost, c)
+	}
+	return cost, true
+}
+
+type parser struct {
+	tokens    []string
+	pos       int
+	variables map[string]any
+	// Position of the selection set of each fragment
+	fragments map[string]int
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) accept(t string) bool {
+	if p.peek() == t {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipBalanced skips a group opened by open up to its matching close
+func (p *parser) skipBalanced(open, close string) {
+	depth := 0
+	for t := p.peek(); t != ""; t = p.peek() {
+		p.pos++
+		switch t {
+		case open:
+			depth++
+		case close:
+			if depth--; depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *parser) directives() {
+	for p.accept("@") {
+		p.pos++
+		if p.peek() == "(" {
+			p.skipBalanced("(", ")")
+		}
+	}
+}
+
+// variableDefaults records the default values of the variables the request
+// leaves out
+func (p *parser) variableDefaults() error {
+	p.pos++
+	for !p.accept(")") {
+		if !p.accept("$") {
+			return errQuerySyntax
+		}
+		name := p.next()
+		for t := p.peek(); t != "=" && t != "$" && t != ")" && t != "@"; t = p.peek() {
+			if t == "" {
+				return errQuerySyntax
+			}
+			p.pos++
+		}
+		if p.accept("=") {
+			value, err := p.value()
+			if err != nil {
+				return err
+			}
+			if _, ok := p.variables[name]; !ok {
+				p.variables[name] = value
+			}
+		}
+		p.directives()
+	}
+	return nil
+}
+
+func (p *parser) selectionSet(multiplier, depth int) (int, error) {
+	if !p.accept("{") || depth > 100 {
+		return 0, errQuerySyntax
+	}
+	cost := 0
+	for !p.accept("}") {
+		var c int
+		var err error
+		switch p.peek() {
+		case "":
+			return 0, errQuerySyntax
+		case "...":
+			c, err = p.fragment(multiplier, depth)
+		default:
+			c, err = p.field(multiplier, depth)
+		}
+		if err != nil {
+			return 0, err
+		}
+		cost = saturatingAdd(cost, c)
+	}
+	return cost, nil
+}
+
+func (p *parser) fragment(multiplier, depth int) (int, error) {
+	p.pos++
+	if p.accept("on") {
+		p.pos++
+	} else if t := p.peek(); t != "@" && t != "{" {
+		p.pos++
+		p.directives()
+		body, ok := p.fragments[t]
+		if !ok {
+			return 0, errQuerySyntax
+		}
+		pos := p.pos
+		p.pos = body
+		cost, err := p.selectionSet(multiplier, depth+1)
+		p.pos = pos
+		return cost, err
+	}
+	p.directives()
+	return p.selectionSet(multiplier, depth+1)
+}
+
+func (p *parser) field(multiplier, depth int) (int, error) {
+	name := p.next()
+	if p.accept(":") {
+		name = p.next()
+	}
+	args := make(map[string]any)
+	if p.accept("(") {
+		for !p.accept(")") {
+			arg := p.next()
+			if arg == "" || !p.accept(":") {
+				return 0, errQuerySyntax
+			}
+			value, err := p.value()
+			if err != nil {
+				return 0, err
+			}
+			args[arg] = value
+		}
+	}
+	p.directives()
+
+	cost := multiplier
+	if p.peek() == "{" {
+		c, err := p.selectionSet(saturatingMul(multiplier, listSize(name, args)), depth+1)
+		if err != nil {
+			return 0, err
+		}
+		cost = saturatingAdd(cost, c)
+	}
+	return cost, nil
+}
+
+// value parses an argument value, only the numbers are kept
+func (p *parser) value() (any, error) {
+	switch t := p.next(); t {
+	case "":
+		return nil, errQuerySyntax
+	case "$":
+		return p.variables[p.next()], nil
+	case "[":
+		p.pos--
+		p.skipBalanced("[", "]")
+	case "{":
+		p.pos--
+		p.skipBalanced("{", "}")
+	default:
+		var n json.Number
+		if err := json.Unmarshal([]byte(t), &n); err == nil {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+// listSize is the number of items a field resolves its selection for
+func listSize(name string, args map[string]any) int {
+	for _, arg := range listSizeArgs {
+		if n, ok := intValue(args[arg]); ok {
+			return max(n, 1)
+		}
+	}
+	if strings.HasPrefix(name, "top") || slices.Contains(listFields, name) {
+		return defaultListSize
+	}
+	return 1
+}
+
+func intValue(v any) (int, bool) {
+	var f float64
+	switch v := v.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		if err != nil {
+			return 0, false
+		}
+		f = n
+	case float64:
+		f = v
+	case int:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	default:
+		return 0, false
+	}
+	return int(min(f, math.MaxInt32)), true
+}
+
+func saturatingAdd(a, b int) int {
+	return min(a+b, math.MaxInt32)
+}
+
+func saturatingMul(a, b int) int {
+	return min(a*b, math.MaxInt32)
+}
+
+// tokenize splits a GraphQL document into its punctuators, names, numbers and
+// quoted strings, dropping the ignored tokens
+func tokenize(src string) ([]string, error) {
+	src = strings.TrimPrefix(src, "\uFEFF")
+	var tokens []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' && src[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(src[i:], `"""`):
+			end := strings.Index(src[i+3:], `"""`)
+			for end >= 0 && src[i+3+end-1] == '\\' {
+				next := strings.Index(src[i+3+end+3:], `"""`)
+				if next < 0 {
+					end = -1
+					break
+				}
+				end += 3 + next
+			}
+			if end < 0 {
+				return nil, errQuerySyntax
+			}
+			tokens = append(tokens, src[i:i+3+end+3])
+			i += 3 + end + 3
+		case c == '"':
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(src) {
+				return nil, errQuerySyntax
+			}
+			tokens = append(tokens, src[i:j+1])
+			i = j + 1
+		case c == '_' || isLetter(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || isLetter(src[j]) || isDigit(src[j])) {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		case c == '-' || isDigit(c):
+			j := i + 1
+			for j < len(src) && (isDigit(src[j]) || src[j] == '.' || src[j] == 'e' || src[j] == 'E' ||
+				((src[j] == '+' || src[j] == '-') && (src[j-1] == 'e' || src[j-1] == 'E'))) {
+				j++
+			}
+			tokens = append(tokens, src[i:j])
+			i = j
+		default:
+			return nil, errQuerySyntax
+		}
+	}
+	return tokens, nil
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
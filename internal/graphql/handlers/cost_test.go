@@ -0,0 +1,73 @@
+package handlers
+
+import "testing"
+
+func TestQueryCost(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		variables map[string]any
+		want      int
+		wantOK    bool
+	}{
+		{name: "fields", query: `{ stats { summary { totalTxs totalUsers } } }`, want: 4, wantOK: true},
+		{name: "default list size", query: `{ chains { id name } }`, want: 21, wantOK: true},
+		{name: "limit argument", query: `{ stats { topUsers(limit: 100) { rank amount } } }`, want: 202, wantOK: true},
+		{name: "aliases add up", query: `{ a: chains { id } b: chains { id } }`, want: 22, wantOK: true},
+		{
+			name:   "nested lists multiply",
+			query:  `{ stats { topUsers(limit: 5) { address { transactions(first: 20) { id } } } } }`,
+			want:   1 + 1 + 5 + 5 + 5*20,
+			wantOK: true,
+		},
+		{
+			name:      "variable",
+			query:     `query Top($n: Int) { stats { topUsers(limit: $n) { rank } } }`,
+			variables: map[string]any{"n": float64(50)},
+			want:      52,
+			wantOK:    true,
+		},
+		{
+			name:   "variable default",
+			query:  `query Top($n: Int = 3, $p: String = "7d") { stats { topUsers(limit: $n, period: $p) { rank } } }`,
+			want:   5,
+			wantOK: true,
+		},
+		{
+			name:   "fragments",
+			query:  `{ stats { topUsers(limit: 2) { ...user ... on AddressAmount { rank } } } } fragment user on AddressAmount { amount chains { amount } }`,
+			want:   1 + 1 + 2*(1+1+10) + 2,
+			wantOK: true,
+		},
+		{
+			name:      "named operation",
+			query:     `query A { chains { id } } query B { stats { summary { totalTxs } } }`,
+			operation: "B",
+			want:      3,
+			wantOK:    true,
+		},
+		{
+			name:   "strings and comments",
+			query:  "# comment\n{ address(address: \"0x{}\") { transactions(first: 1, type: BRIDGE) { id } } }",
+			want:   3,
+			wantOK: true,
+		},
+		{
+			name:   "saturates",
+			query:  `{ a(first: 100000) { b(first: 100000) { c(first: 100000) { d } } } }`,
+			want:   2147483647,
+			wantOK: true,
+		},
+		{name: "unterminated", query: `{ chains { id }`, wantOK: false},
+		{name: "unknown fragment", query: `{ ...missing }`, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := queryCost(tt.query, tt.operation, tt.variables)
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("queryCost() = (%d, %v), want (%d, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/internal/graphql/resolvers"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+var schema = graphql.MustParseSchema(resolvers.Schema, &resolvers.Resolver{},
+	graphql.MaxDepth(10),
+	graphql.MaxParallelism(20),
+)
+
+type QueryOptions struct {
+	Query         string         `json:"query" query:"query" validate:"required"`
+	OperationName string         `json:"operationName" query:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Query executes a GraphQL query sent as a JSON body, or as query params with
+// variables JSON encoded for GET requests
+func Query(c echo.Context) error {
+	var opts QueryOptions
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+	if variables := c.QueryParam("variables"); c.Request().Method == http.MethodGet && variables != "" {
+		if err := json.Unmarshal([]byte(variables), &opts.Variables); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "variables must be a JSON object")
+		}
+	}
+
+	if cost, ok := queryCost(opts.Query, opts.OperationName, opts.Variables); ok && cost > config.Env.GRAPHQL_MAX_COST {
+		return c.JSON(http.StatusOK, &graphql.Response{Errors: []*gqlerrors.QueryError{
+			gqlerrors.Errorf("query cost %d exceeds the limit of %d", cost, config.Env.GRAPHQL_MAX_COST),
+		}})
+	}
+
+	ctx := resolvers.WithLoaders(c.Request().Context())
+	response := schema.Exec(ctx, opts.Query, opts.OperationName, opts.Variables)
+	return c.JSON(http.StatusOK, response)
+}
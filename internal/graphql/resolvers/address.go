@@ -0,0 +1,56 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/identity"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+type AddressResolver struct {
+	address string
+}
+
+func (a *AddressResolver) Address() string {
+	return a.address
+}
+
+func (a *AddressResolver) Transactions(ctx context.Context, args struct {
+	Type  *string
+	First *int32
+}) ([]*TransactionResolver, error) {
+	first, err := intArg("first", args.First, 10, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+	key, err := addressKey(a.address)
+	if err != nil {
+		return nil, err
+	}
+
+	k := txsKey{Address: key, First: first}
+	if args.Type != nil {
+		k.Type = txType(*args.Type)
+	}
+	txs, err := loaders(ctx).Txs.Load(ctx, k)()
+	if err != nil {
+		return nil, err
+	}
+	return newTransactions(txs), nil
+}
+
+// addressKey is how an address is stored in the transaction tables: lowercase
+// for EVM and as a script pubkey for bitcoin
+func addressKey(address string) (string, error) {
+	address = identity.Normalize(address)
+	if identity.IsEVMAddress(address) {
+		return address, nil
+	}
+	script, err := utils.AddressToScriptPubKey(address, config.Env.BITCOIN_CHAIN_ID)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q", address)
+	}
+	return script, nil
+}
@@ -0,0 +1,13 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/scalarorg/scalar-service/pkg/cache"
+)
+
+// cached serves the result of load from the response cache entry of the REST
+// route answering the same options, so both APIs share their entries
+func cached[T any](ctx context.Context, route string, policy cache.Policy, opts any, load func(ctx context.Context) (T, error)) (T, error) {
+	return cache.Remember(ctx, cache.Default, cache.Key(route, opts), policy, load)
+}
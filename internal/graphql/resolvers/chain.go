@@ -0,0 +1,45 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+// ChainResolver loads the stats of the chain only when one of them is selected
+type ChainResolver struct {
+	id string
+}
+
+// newChain resolves a chain by its canonical id, whichever form it is stored in
+func newChain(id string) *ChainResolver {
+	return &ChainResolver{id: chainid.Normalize(id)}
+}
+
+func (c *ChainResolver) ID() string {
+	return c.id
+}
+
+func (c *ChainResolver) Name() string {
+	return db.ChainName(c.id)
+}
+
+func (c *ChainResolver) stats(ctx context.Context) (db.ChainStats, error) {
+	return loaders(ctx).Chains.Load(ctx, c.id)()
+}
+
+func (c *ChainResolver) Txs(ctx context.Context) (float64, error) {
+	s, err := c.stats(ctx)
+	return float64(s.Txs), err
+}
+
+func (c *ChainResolver) Volume(ctx context.Context) (float64, error) {
+	s, err := c.stats(ctx)
+	return float64(s.Volume), err
+}
+
+func (c *ChainResolver) Users(ctx context.Context) (float64, error) {
+	s, err := c.stats(ctx)
+	return float64(s.Users), err
+}
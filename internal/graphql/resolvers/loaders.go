@@ -0,0 +1,140 @@
+package resolvers
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+var txTypes = []db.CrossChainTx{db.CrossChainTxBridge, db.CrossChainTxTransfer, db.CrossChainTxRedeem}
+
+type loadersKey struct{}
+
+// Loaders batch the database lookups of nested fields, they live for one
+// request so results are never shared between requests
+type Loaders struct {
+	Chains *dataloader.Loader[string, db.ChainStats]
+	Txs    *dataloader.Loader[txsKey, []db.BaseCrossChainTxResult]
+}
+
+// txsKey looks up the latest transactions of an address, Type is empty for
+// every type
+type txsKey struct {
+	Address string
+	Type    db.CrossChainTx
+	First   int
+}
+
+func NewLoaders() *Loaders {
+	return &Loaders{
+		Chains: dataloader.NewBatchedLoader(loadChains),
+		Txs:    dataloader.NewBatchedLoader(loadTxs),
+	}
+}
+
+func WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, NewLoaders())
+}
+
+func loaders(ctx context.Context) *Loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*Loaders); ok {
+		return l
+	}
+	return NewLoaders()
+}
+
+func loadChains(ctx context.Context, ids []string) []*dataloader.Result[db.ChainStats] {
+	results := make([]*dataloader.Result[db.ChainStats], len(ids))
+	stats, err := db.GetChainStats(ctx, ids)
+	if err != nil {
+		for i := range results {
+			results[i] = &dataloader.Result[db.ChainStats]{Error: err}
+		}
+		return results
+	}
+
+	byChain := make(map[string]db.ChainStats, len(stats))
+	for _, s := range stats {
+		byChain[s.Chain] = s
+	}
+	for i, id := range ids {
+		id = chainid.Normalize(id)
+		s, ok := byChain[id]
+		if !ok {
+			s = db.ChainStats{Chain: id}
+		}
+		results[i] = &dataloader.Result[db.ChainStats]{Data: s}
+	}
+	return results
+}
+
+// loadTxs runs one query per type for all the addresses of the batch, with
+// the largest first of the batch, then splits the rows back per key
+func loadTxs(ctx context.Context, keys []txsKey) []*dataloader.Result[[]db.BaseCrossChainTxResult] {
+	first := 0
+	addresses := make(map[db.CrossChainTx][]string)
+	seen := make(map[txsKey]bool)
+	for _, key := range keys {
+		first = max(first, key.First)
+		for _, typ := range txTypes {
+			if key.Type != "" && key.Type != typ {
+				continue
+			}
+			k := txsKey{Address: key.Address, Type: typ}
+			if !seen[k] {
+				seen[k] = true
+				addresses[typ] = append(addresses[typ], key.Address)
+			}
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	lock := sync.Mutex{}
+	var errs []error
+	rows := make(map[txsKey][]db.BaseCrossChainTxResult)
+	for typ, addrs := range addresses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			txs, err := db.ListTxsByAddresses(ctx, typ, addrs, first)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			for _, tx := range txs {
+				k := txsKey{Address: tx.Address, Type: typ}
+				rows[k] = append(rows[k], tx.BaseCrossChainTxResult)
+			}
+		}()
+	}
+	wg.Wait()
+
+	results := make([]*dataloader.Result[[]db.BaseCrossChainTxResult], len(keys))
+	for i, key := range keys {
+		if len(errs) > 0 {
+			results[i] = &dataloader.Result[[]db.BaseCrossChainTxResult]{Error: errs[0]}
+			continue
+		}
+
+		txs := make([]db.BaseCrossChainTxResult, 0)
+		for _, typ := range txTypes {
+			if key.Type == "" || key.Type == typ {
+				txs = append(txs, rows[txsKey{Address: key.Address, Type: typ}]...)
+			}
+		}
+		sort.SliceStable(txs, func(i, j int) bool {
+			return txs[i].BlockTime > txs[j].BlockTime
+		})
+		if len(txs) > key.First {
+			txs = txs[:key.First]
+		}
+		results[i] = &dataloader.Result[[]db.BaseCrossChainTxResult]{Data: txs}
+	}
+	return results
+}
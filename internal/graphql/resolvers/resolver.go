@@ -0,0 +1,137 @@
+package resolvers
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+	"gorm.io/gorm"
+)
+
+//go:embed schema.graphql
+var Schema string
+
+var validate = utils.NewValidator()
+
+// Resolver resolves the root query, nested fields batch their database access
+// through the loaders of the request context
+type Resolver struct{}
+
+func txType(t string) db.CrossChainTx {
+	return db.CrossChainTx(strings.ToLower(t))
+}
+
+// intArg returns an optional int argument, bounded to [min, max]
+func intArg(name string, v *int32, def, min, max int) (int, error) {
+	if v == nil {
+		return def, nil
+	}
+	if int(*v) < min || int(*v) > max {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, min, max)
+	}
+	return int(*v), nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct {
+	Type   string
+	TxHash string
+}) (*TransactionResolver, error) {
+	var (
+		tx  *db.BaseCrossChainTxResult
+		err error
+	)
+
+	switch txType(args.Type) {
+	case db.CrossChainTxBridge:
+		tx, err = db.GetBridgeTx(ctx, args.TxHash)
+	case db.CrossChainTxTransfer:
+		tx, err = db.GetTransferTx(ctx, args.TxHash)
+	case db.CrossChainTxRedeem:
+		tx, err = db.GetRedeemTx(ctx, args.TxHash)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newTransaction(tx), nil
+}
+
+type TransactionPageResolver struct {
+	total int
+	items []*TransactionResolver
+}
+
+func (p *TransactionPageResolver) Total() int32 {
+	return int32(p.total)
+}
+
+func (p *TransactionPageResolver) Items() []*TransactionResolver {
+	return p.items
+}
+
+func (r *Resolver) Transactions(ctx context.Context, args struct {
+	Type string
+	Page *int32
+	Size *int32
+}) (*TransactionPageResolver, error) {
+	size, err := intArg("size", args.Size, 10, 1, 100)
+	if err != nil {
+		return nil, err
+	}
+	page, err := intArg("page", args.Page, 0, 0, 1<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		txs   []db.BaseCrossChainTxResult
+		count int
+	)
+	switch txType(args.Type) {
+	case db.CrossChainTxBridge:
+		txs, count, err = db.ListBridgeTxs(ctx, size, page*size)
+	case db.CrossChainTxTransfer:
+		txs, count, err = db.ListTransferTxs(ctx, size, page*size)
+	case db.CrossChainTxRedeem:
+		txs, count, err = db.ListRedeemTxs(ctx, size, page*size)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransactionPageResolver{total: count, items: newTransactions(txs)}, nil
+}
+
+func (r *Resolver) Address(args struct{ Address string }) *AddressResolver {
+	return &AddressResolver{address: args.Address}
+}
+
+func (r *Resolver) Chain(args struct{ ID string }) *ChainResolver {
+	return newChain(args.ID)
+}
+
+func (r *Resolver) Chains(ctx context.Context) ([]*ChainResolver, error) {
+	stats, err := db.GetChainStats(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := loaders(ctx)
+	chains := make([]*ChainResolver, len(stats))
+	for i, s := range stats {
+		l.Chains.Prime(ctx, s.Chain, s)
+		chains[i] = newChain(s.Chain)
+	}
+	return chains, nil
+}
+
+func (r *Resolver) Stats() *StatsResolver {
+	return &StatsResolver{}
+}
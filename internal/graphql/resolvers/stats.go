@@ -0,0 +1,275 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/scalarorg/scalar-service/constants"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/price"
+	"github.com/scalarorg/scalar-service/pkg/types"
+)
+
+type StatsResolver struct{}
+
+type leaderboardArgs struct {
+	Limit  *int32
+	Page   *int32
+	Period *string
+}
+
+// opts validates the arguments the same way the REST leaderboards do
+func (a leaderboardArgs) opts() (*services.LeaderboardOpts, error) {
+	var opts services.LeaderboardOpts
+	if a.Limit != nil {
+		opts.Limit = int(*a.Limit)
+	}
+	if a.Page != nil {
+		opts.Page = int(*a.Page)
+	}
+	if a.Period != nil {
+		opts.Period = *a.Period
+	}
+	if err := validate.Validate(&opts); err != nil {
+		return nil, err
+	}
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+func (s *StatsResolver) Summary(ctx context.Context, args struct {
+	Network *string
+	Usd     *bool
+}) (*SummaryResolver, error) {
+	var opts services.StatsOpts
+	if args.Network != nil {
+		opts.Network = *args.Network
+	}
+	if args.Usd != nil {
+		opts.USD = *args.Usd
+	}
+	if err := validate.Validate(&opts); err != nil {
+		return nil, err
+	}
+	if opts.USD && !price.Enabled() {
+		return nil, constants.ErrPriceDisabled
+	}
+
+	switch opts.Network {
+	case "mainnet":
+		opts.Network = "bitcoin|1"
+	default:
+		opts.Network = constants.DefaultChain
+	}
+
+	summary, err := cached(ctx, "/api/stats/summary", services.SummaryCachePolicy(), services.SummaryCacheKey(&opts), func(ctx context.Context) (*services.SummaryStats, error) {
+		return services.GetSummaryStats(ctx, &opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SummaryResolver{summary: summary}, nil
+}
+
+func (s *StatsResolver) TopUsers(ctx context.Context, args leaderboardArgs) ([]*AddressAmountResolver, error) {
+	opts, err := args.opts()
+	if err != nil {
+		return nil, err
+	}
+	users, err := cached(ctx, "/api/stats/volume/top-users", services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]types.AddressAmount, error) {
+		return services.GetTopUsersByVolume(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*AddressAmountResolver, len(users))
+	for i := range users {
+		resolvers[i] = &AddressAmountResolver{amount: &users[i]}
+	}
+	return resolvers, nil
+}
+
+func (s *StatsResolver) TopBridges(ctx context.Context, args struct {
+	Chain  *string
+	Limit  *int32
+	Page   *int32
+	Period *string
+}) ([]*AddressAmountResolver, error) {
+	opts, err := leaderboardArgs{Limit: args.Limit, Page: args.Page, Period: args.Period}.opts()
+	if err != nil {
+		return nil, err
+	}
+	opts.Chain = constants.DefaultChain
+	if args.Chain != nil {
+		opts.Chain = *args.Chain
+	}
+	bridges, err := cached(ctx, "/api/stats/volume/top-bridges", services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.AddressAmount, error) {
+		return services.GetTopBridgesByVolume(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return addressAmounts(bridges), nil
+}
+
+func (s *StatsResolver) TopSourceChainsByVolume(ctx context.Context, args leaderboardArgs) ([]*ChainAmountResolver, error) {
+	return chainLeaderboard(ctx, "/api/stats/volume/top-source-chains", args, services.GetTopSourceChainsByVolume)
+}
+
+func (s *StatsResolver) TopDestinationChainsByVolume(ctx context.Context, args leaderboardArgs) ([]*ChainAmountResolver, error) {
+	return chainLeaderboard(ctx, "/api/stats/volume/top-destination-chains", args, services.GetTopDestinationChainsByVolume)
+}
+
+func (s *StatsResolver) TopPathsByVolume(ctx context.Context, args leaderboardArgs) ([]*PathAmountResolver, error) {
+	return pathLeaderboard(ctx, "/api/stats/volume/top-paths", args, services.GetTopPathsByVolume)
+}
+
+func (s *StatsResolver) TopSourceChainsByTx(ctx context.Context, args leaderboardArgs) ([]*ChainAmountResolver, error) {
+	return chainLeaderboard(ctx, "/api/stats/transaction/top-source-chains", args, services.StatTransactionBySourceChain)
+}
+
+func (s *StatsResolver) TopDestinationChainsByTx(ctx context.Context, args leaderboardArgs) ([]*ChainAmountResolver, error) {
+	return chainLeaderboard(ctx, "/api/stats/transaction/top-destination-chains", args, services.StatTransactionByDestinationChain)
+}
+
+func (s *StatsResolver) TopPathsByTx(ctx context.Context, args leaderboardArgs) ([]*PathAmountResolver, error) {
+	return pathLeaderboard(ctx, "/api/stats/transaction/top-paths", args, services.StatTransactionByPath)
+}
+
+// chainLeaderboard loads a leaderboard through the response cache of its REST
+// route
+func chainLeaderboard(ctx context.Context, route string, args leaderboardArgs, load func(context.Context, *services.LeaderboardOpts) ([]*types.ChainAmount, error)) ([]*ChainAmountResolver, error) {
+	opts, err := args.opts()
+	if err != nil {
+		return nil, err
+	}
+	chains, err := cached(ctx, route, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.ChainAmount, error) {
+		return load(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*ChainAmountResolver, len(chains))
+	for i, c := range chains {
+		resolvers[i] = &ChainAmountResolver{amount: c}
+	}
+	return resolvers, nil
+}
+
+func pathLeaderboard(ctx context.Context, route string, args leaderboardArgs, load func(context.Context, *services.LeaderboardOpts) ([]*types.PathAmount, error)) ([]*PathAmountResolver, error) {
+	opts, err := args.opts()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := cached(ctx, route, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.PathAmount, error) {
+		return load(ctx, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*PathAmountResolver, len(paths))
+	for i, p := range paths {
+		resolvers[i] = &PathAmountResolver{amount: p}
+	}
+	return resolvers, nil
+}
+
+func addressAmounts(amounts []*types.AddressAmount) []*AddressAmountResolver {
+	resolvers := make([]*AddressAmountResolver, len(amounts))
+	for i, a := range amounts {
+		resolvers[i] = &AddressAmountResolver{amount: a}
+	}
+	return resolvers
+}
+
+type SummaryResolver struct {
+	summary *services.SummaryStats
+}
+
+func (s *SummaryResolver) TotalTxs() float64 {
+	return float64(s.summary.TotalTxs)
+}
+
+func (s *SummaryResolver) TotalVolumes() float64 {
+	return float64(s.summary.TotalVolumes)
+}
+
+func (s *SummaryResolver) TotalVolumesUsd() *float64 {
+	return s.summary.TotalVolumesUSD
+}
+
+func (s *SummaryResolver) TotalUsers() float64 {
+	return float64(s.summary.TotalUsers)
+}
+
+type AddressAmountResolver struct {
+	amount *types.AddressAmount
+}
+
+func (a *AddressAmountResolver) Rank() int32 {
+	return int32(a.amount.Rank)
+}
+
+func (a *AddressAmountResolver) Address() *AddressResolver {
+	return &AddressResolver{address: a.amount.Address}
+}
+
+func (a *AddressAmountResolver) Amount() float64 {
+	return float64(a.amount.Amount)
+}
+
+func (a *AddressAmountResolver) Addresses() []*AddressResolver {
+	addresses := make([]*AddressResolver, len(a.amount.Addresses))
+	for i, address := range a.amount.Addresses {
+		addresses[i] = &AddressResolver{address: address}
+	}
+	return addresses
+}
+
+func (a *AddressAmountResolver) Chains() []*ChainAmountResolver {
+	chains := make([]*ChainAmountResolver, len(a.amount.Chains))
+	for i := range a.amount.Chains {
+		chains[i] = &ChainAmountResolver{amount: &a.amount.Chains[i]}
+	}
+	return chains
+}
+
+type ChainAmountResolver struct {
+	amount *types.ChainAmount
+}
+
+func (c *ChainAmountResolver) Rank() int32 {
+	return int32(c.amount.Rank)
+}
+
+func (c *ChainAmountResolver) Chain() *ChainResolver {
+	return newChain(c.amount.Chain)
+}
+
+func (c *ChainAmountResolver) Amount() float64 {
+	return float64(c.amount.Amount)
+}
+
+type PathAmountResolver struct {
+	amount *types.PathAmount
+}
+
+func (p *PathAmountResolver) Rank() int32 {
+	return int32(p.amount.Rank)
+}
+
+func (p *PathAmountResolver) Source() *ChainResolver {
+	return newChain(p.amount.SourceChain)
+}
+
+func (p *PathAmountResolver) Destination() *ChainResolver {
+	return newChain(p.amount.DestinationChain)
+}
+
+func (p *PathAmountResolver) Amount() float64 {
+	return float64(p.amount.Amount)
+}
@@ -0,0 +1,149 @@
+package resolvers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/price"
+)
+
+type TransactionResolver struct {
+	doc *db.CrossChainDocument
+}
+
+func newTransaction(tx *db.BaseCrossChainTxResult) *TransactionResolver {
+	return &TransactionResolver{doc: db.CreateCrossChainDocument(tx)}
+}
+
+func newTransactions(txs []db.BaseCrossChainTxResult) []*TransactionResolver {
+	resolvers := make([]*TransactionResolver, len(txs))
+	for i := range txs {
+		resolvers[i] = newTransaction(&txs[i])
+	}
+	return resolvers
+}
+
+func (t *TransactionResolver) ID() string {
+	return t.doc.ID
+}
+
+func (t *TransactionResolver) Type() string {
+	return strings.ToUpper(string(t.doc.Type))
+}
+
+func (t *TransactionResolver) Status() string {
+	return t.doc.Status
+}
+
+func (t *TransactionResolver) CommandID() string {
+	return t.doc.CommandID
+}
+
+func (t *TransactionResolver) Source() *EndpointResolver {
+	return &EndpointResolver{
+		base:    t.doc.Source.BaseDocument,
+		address: t.doc.Source.Sender,
+		source:  t.doc.Source.BaseDocument,
+	}
+}
+
+func (t *TransactionResolver) Destination() *EndpointResolver {
+	return &EndpointResolver{
+		base:    t.doc.Destination.BaseDocument,
+		address: t.doc.Destination.Receiver,
+		source:  t.doc.Source.BaseDocument,
+	}
+}
+
+// EndpointResolver resolves one side of a transaction, source is kept to value
+// both sides at the source block time
+type EndpointResolver struct {
+	base    *db.BaseDocument
+	address string
+	source  *db.BaseDocument
+}
+
+func (e *EndpointResolver) Chain() *ChainResolver {
+	return newChain(e.base.Chain)
+}
+
+func (e *EndpointResolver) Address() *AddressResolver {
+	return &AddressResolver{address: e.address}
+}
+
+func (e *EndpointResolver) TxHash() string {
+	return e.base.TxHash
+}
+
+func (e *EndpointResolver) BlockHeight() float64 {
+	return float64(e.base.BlockHeight)
+}
+
+func (e *EndpointResolver) BlockTime() float64 {
+	return float64(e.base.BlockTime)
+}
+
+func (e *EndpointResolver) Status() string {
+	return e.base.Status
+}
+
+func (e *EndpointResolver) Value() string {
+	return e.base.Value
+}
+
+func (e *EndpointResolver) Fee() string {
+	return e.base.Fee
+}
+
+func (e *EndpointResolver) Asset() *AssetResolver {
+	return &AssetResolver{asset: e.base.CrossChainAsset}
+}
+
+// Usd is only computed when selected, a missing price resolves to null rather
+// than failing the query
+func (e *EndpointResolver) Usd(ctx context.Context) *float64 {
+	if !price.Enabled() || e.source.BlockTime == 0 {
+		return nil
+	}
+
+	amount, err := strconv.ParseUint(e.source.Value, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	asset := e.source.CrossChainAsset
+	usd, err := price.USD(ctx, asset.Symbol, amount, asset.Decimals, time.Unix(int64(e.source.BlockTime), 0))
+	if err != nil {
+		log.Warn().Err(err).Str("tx_hash", e.source.TxHash).Msg("failed to value transaction in usd")
+		return nil
+	}
+	return &usd
+}
+
+type AssetResolver struct {
+	asset db.CrossChainAsset
+}
+
+func (a *AssetResolver) Name() string {
+	return a.asset.Name
+}
+
+func (a *AssetResolver) Symbol() string {
+	return a.asset.Symbol
+}
+
+func (a *AssetResolver) Address() string {
+	return a.asset.Address
+}
+
+func (a *AssetResolver) Decimals() int32 {
+	return int32(a.asset.Decimals)
+}
+
+func (a *AssetResolver) IsNative() bool {
+	return a.asset.IsNative
+}
@@ -0,0 +1,15 @@
+package graphql
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/graphql/handlers"
+	"github.com/scalarorg/scalar-service/internal/middleware"
+	"github.com/scalarorg/scalar-service/pkg/apikey"
+)
+
+func Route(e *echo.Echo, path string) {
+	// Queries can fetch every resource at once, they need the graphql scope
+	requireScope := middleware.RequireScope(apikey.ScopeGraphQL)
+	e.GET(path, handlers.Query, requireScope)
+	e.POST(path, handlers.Query, requireScope)
+}
@@ -0,0 +1,255 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/openobserve"
+	"gorm.io/gorm"
+)
+
+const checkTimeout = 2 * time.Second
+
+// reportTTL is how long the report of every component is reused, so that
+// health requests can't load the databases and OpenObserve
+const reportTTL = 5 * time.Second
+
+// readinessTTL is how long the report of the critical components is reused, it
+// is shorter so that probes still notice an outage within a second
+const readinessTTL = time.Second
+
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// Component is the result of one check. Details holds what the check measured
+// and Error why it failed.
+type Component struct {
+	Status    Status `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Details   any    `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status     Status                `json:"status"`
+	Components map[string]*Component `json:"components,omitempty"`
+}
+
+// HTTPStatus is 503 when a critical component is down, a degraded service is
+// still ready
+func (r *Report) HTTPStatus() int {
+	if r.Status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// Summary is the report without the measures and errors of the components,
+// which may reveal the internals of the service and are only shown to admins
+func (r *Report) Summary() *Report {
+	summary := &Report{Status: r.Status, Components: make(map[string]*Component, len(r.Components))}
+	for name, component := range r.Components {
+		summary.Components[name] = &Component{Status: component.Status, Critical: component.Critical}
+	}
+	return summary
+}
+
+type PoolStats struct {
+	MaxOpen        int   `json:"max_open"`
+	Open           int   `json:"open"`
+	InUse          int   `json:"in_use"`
+	Idle           int   `json:"idle"`
+	WaitCount      int64 `json:"wait_count"`
+	WaitDurationMs int64 `json:"wait_duration_ms"`
+}
+
+type check struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) (any, error)
+}
+
+// checks are the critical checks first, then the ones that only degrade the
+// service when they fail
+func checks() []check {
+	all := []check{
+		{"relayer_db", true, func(ctx context.Context) (any, error) { return pingDB(ctx, db.DB.Relayer) }},
+		{"indexer_db", true, func(ctx context.Context) (any, error) { return pingDB(ctx, db.DB.Indexer) }},
+		{"indexer_freshness", false, checkFreshness},
+	}
+	if config.Env.UsesOpenObserve() {
+		all = append(all, check{"openobserve", false, checkOpenObserve})
+	}
+	return all
+}
+
+// Check runs the checks concurrently, each bounded by checkTimeout. Without
+// all only the critical ones are run.
+func Check(ctx context.Context, all bool) *Report {
+	report := &Report{Status: StatusUp, Components: make(map[string]*Component)}
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for _, chk := range checks() {
+		if !all && !chk.critical {
+			continue
+		}
+		wg.Add(1)
+		go func(chk check) {
+			defer wg.Done()
+			component := runCheck(ctx, chk)
+			lock.Lock()
+			report.Components[chk.name] = component
+			lock.Unlock()
+		}(chk)
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		switch {
+		case component.Status == StatusUp:
+		case component.Critical:
+			report.Status = StatusDown
+		case report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// cachedReport is a report checked at most once per ttl. Concurrent requests
+// wait for the same check, which outlives the request that started it.
+type cachedReport struct {
+	all    bool
+	ttl    time.Duration
+	lock   sync.Mutex
+	report *Report
+	at     time.Time
+}
+
+func (r *cachedReport) get(ctx context.Context) *Report {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.report == nil || time.Since(r.at) >= r.ttl {
+		r.report = Check(context.WithoutCancel(ctx), r.all)
+		r.at = time.Now()
+	}
+	return r.report
+}
+
+var (
+	fullReport      = &cachedReport{all: true, ttl: reportTTL}
+	readinessReport = &cachedReport{ttl: readinessTTL}
+)
+
+// CachedCheck returns the report of every component, checked at most once per
+// reportTTL
+func CachedCheck(ctx context.Context) *Report {
+	return fullReport.get(ctx)
+}
+
+// CachedReadiness returns the report of the critical components, checked at
+// most once per readinessTTL
+func CachedReadiness(ctx context.Context) *Report {
+	return readinessReport.get(ctx)
+}
+
+func runCheck(ctx context.Context, chk check) *Component {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := chk.run(ctxWithTimeout)
+	component := &Component{
+		Status:    StatusUp,
+		Critical:  chk.critical,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Details:   details,
+	}
+	if err != nil {
+		component.Status = StatusDown
+		if !chk.critical {
+			component.Status = StatusDegraded
+		}
+		component.Error = err.Error()
+	}
+	return component
+}
+
+// pingDB pings a database and reports the stats of its connection pool
+func pingDB(ctx context.Context, conn *gorm.DB) (any, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+	sqlDB, err := conn.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := sqlDB.Stats()
+	pool := &PoolStats{
+		MaxOpen:        stats.MaxOpenConnections,
+		Open:           stats.OpenConnections,
+		InUse:          stats.InUse,
+		Idle:           stats.Idle,
+		WaitCount:      stats.WaitCount,
+		WaitDurationMs: stats.WaitDuration.Milliseconds(),
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return pool, fmt.Errorf("failed to ping: %w", err)
+	}
+	return pool, nil
+}
+
+// checkFreshness reports the age of the latest block indexed on each chain and
+// fails when one is older than HEALTH_MAX_INDEXER_LAG
+func checkFreshness(ctx context.Context) (any, error) {
+	blocks, err := db.GetLatestBlocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	maxLag := int64(config.Env.HEALTH_MAX_INDEXER_LAG)
+	lags := make(map[string]int64, len(blocks))
+	stale := make([]string, 0)
+	for _, block := range blocks {
+		lags[block.Chain] = now - block.BlockTime
+		if lags[block.Chain] > maxLag {
+			stale = append(stale, block.Chain)
+		}
+	}
+	if len(stale) > 0 {
+		return lags, fmt.Errorf("indexer lags more than %ds on %v", maxLag, stale)
+	}
+	return lags, nil
+}
+
+// checkOpenObserve checks the OpenObserve endpoint the logs and traces are
+// exported to answers without a server error
+func checkOpenObserve(ctx context.Context) (any, error) {
+	endpoint := openobserve.GetConfig().Endpoint
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/healthz", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openobserve is unreachable: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("openobserve answered %s", res.Status)
+	}
+	return nil, nil
+}
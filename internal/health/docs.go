@@ -0,0 +1,12 @@
+package health
+
+import "github.com/scalarorg/scalar-service/pkg/openapi"
+
+func Docs() []openapi.Operation {
+	return []openapi.Operation{
+		{Handler: HealthCheck, Tag: "health", Summary: "Health of every component", Description: "503 when a critical component is down. Checked at most every 5 seconds.", Response: Report{}},
+		{Handler: HealthDetails, Tag: "health", Summary: "Health of every component with their measures and errors", Response: Report{}, Security: openapi.SecurityAdmin},
+		{Handler: LivenessCheck, Tag: "health", Summary: "Liveness probe", Response: Report{}},
+		{Handler: ReadinessCheck, Tag: "health", Summary: "Readiness probe", Description: "503 when a critical component is down or the server is shutting down.", Response: Report{}},
+	}
+}
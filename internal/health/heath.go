@@ -1,12 +1,61 @@
 package health
 
 import (
+	"net/http"
+	"sync/atomic"
+
 	"github.com/labstack/echo/v4"
 )
 
+var shuttingDown atomic.Bool
+
+// SetShuttingDown makes the readiness probe fail so no new request is routed
+// to the server while it drains
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+func shutdownReport() *Report {
+	return &Report{
+		Status: StatusDown,
+		Components: map[string]*Component{
+			"server": {Status: StatusDown, Critical: true, Error: "shutting down"},
+		},
+	}
+}
+
+// HealthCheck reports the status of every component, it is unavailable when a
+// critical one is down and only degraded when a non critical one is
 func HealthCheck(c echo.Context) error {
-	// TODO: Implement health check logic here
-	// Return OK if everything is fine
-	// Otherwise, return an error
-	return c.JSON(200, "OK")
+	if shuttingDown.Load() {
+		return c.JSON(http.StatusServiceUnavailable, shutdownReport())
+	}
+	report := CachedCheck(c.Request().Context())
+	return c.JSON(report.HTTPStatus(), report.Summary())
+}
+
+// HealthDetails is HealthCheck with the measures and errors of the components,
+// for admins
+func HealthDetails(c echo.Context) error {
+	if shuttingDown.Load() {
+		return c.JSON(http.StatusServiceUnavailable, shutdownReport())
+	}
+	report := CachedCheck(c.Request().Context())
+	return c.JSON(report.HTTPStatus(), report)
+}
+
+// LivenessCheck only tells the process serves requests, a dependency outage
+// must not get it restarted
+func LivenessCheck(c echo.Context) error {
+	return c.JSON(http.StatusOK, &Report{Status: StatusUp})
+}
+
+// ReadinessCheck tells whether requests can be served, checking the critical
+// components only
+func ReadinessCheck(c echo.Context) error {
+	if shuttingDown.Load() {
+		return c.JSON(http.StatusServiceUnavailable, shutdownReport())
+	}
+	report := CachedReadiness(c.Request().Context())
+	return c.JSON(report.HTTPStatus(), report.Summary())
 }
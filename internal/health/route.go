@@ -2,8 +2,12 @@ package health
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/middleware"
 )
 
 func Route(e *echo.Echo, path string) {
 	e.GET(path, HealthCheck)
+	e.GET(path+"/live", LivenessCheck)
+	e.GET(path+"/ready", ReadinessCheck)
+	e.GET(path+"/details", HealthDetails, middleware.AdminAuth())
 }
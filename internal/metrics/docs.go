@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"github.com/scalarorg/scalar-service/internal/metrics/handlers"
+	"github.com/scalarorg/scalar-service/pkg/openapi"
+)
+
+func Docs() []openapi.Operation {
+	return []openapi.Operation{
+		{Handler: handlers.Metrics, Summary: "Prometheus metrics", ContentType: "text/plain"},
+	}
+}
@@ -0,0 +1,12 @@
+package handlers
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/pkg/metrics"
+)
+
+// Metrics serves the Prometheus exposition of the protocol metrics
+func Metrics(c echo.Context) error {
+	metrics.Handler().ServeHTTP(c.Response(), c.Request())
+	return nil
+}
@@ -0,0 +1,10 @@
+package metrics
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/metrics/handlers"
+)
+
+func Route(e *echo.Echo, path string) {
+	e.GET(path, handlers.Metrics)
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/config"
+)
+
+// AdminAuth only lets through requests carrying the configured ADMIN_TOKEN as
+// a bearer token. Admin routes are disabled when no token is configured.
+func AdminAuth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Env.ADMIN_TOKEN == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "Admin API is disabled")
+			}
+
+			token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(config.Env.ADMIN_TOKEN)) != 1 {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid admin token")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/pkg/apikey"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+const apiKeyContextKey = "api_key"
+
+// APIKeyAuth identifies the requests carrying an API key in X-API-Key, they
+// are tagged with the key in the logs and traces and counted in its usage.
+// Requests without a key are anonymous, an invalid key is rejected once the
+// request is charged to its IP. Keys are ignored while they are disabled.
+func APIKeyAuth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			secret := c.Request().Header.Get(HeaderAPIKey)
+			if secret == "" {
+				return next(c)
+			}
+
+			key, err := apikey.Authenticate(c.Request().Context(), secret)
+			if errors.Is(err, apikey.ErrDisabled) {
+				return next(c)
+			}
+			if errors.Is(err, apikey.ErrInvalidKey) || errors.Is(err, apikey.ErrRevokedKey) || errors.Is(err, apikey.ErrExpiredKey) {
+				if limitErr := chargeIP(c); limitErr != nil {
+					return limitErr
+				}
+				return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+
+			c.Set(apiKeyContextKey, key)
+			c.Set(utils.APIKeyContextKey, key.Prefix)
+			trace.SpanFromContext(c.Request().Context()).SetAttributes(
+				attribute.Int64("api_key.id", int64(key.ID)),
+				attribute.String("api_key.prefix", key.Prefix),
+				attribute.String("api_key.tier", key.Tier),
+			)
+			apikey.Record(key)
+			return next(c)
+		}
+	}
+}
+
+// RequireScope only lets through the requests whose API key has scope
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := APIKeyFromContext(c)
+			if key == nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "An API key with the "+scope+" scope is required")
+			}
+			if !apikey.HasScope(key, scope) {
+				return echo.NewHTTPError(http.StatusForbidden, "API key lacks the "+scope+" scope")
+			}
+			return next(c)
+		}
+	}
+}
+
+// APIKeyFromContext returns the API key of the request, nil when anonymous
+func APIKeyFromContext(c echo.Context) *db.APIKey {
+	key, _ := c.Get(apiKeyContextKey).(*db.APIKey)
+	return key
+}
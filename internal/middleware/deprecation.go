@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+const (
+	HeaderDeprecation = "Deprecation"
+	HeaderSunset      = "Sunset"
+	HeaderLink        = "Link"
+)
+
+// Deprecated marks the responses of the legacy API as deprecated since
+// LEGACY_API_DEPRECATION, linking to the route of the versioned API replacing
+// them, and announces their removal on LEGACY_API_SUNSET when set
+func Deprecated() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			path := c.Request().URL.Path
+			if utils.IsV1(c) || !strings.HasPrefix(path, "/api/") {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			if deprecation, err := time.Parse(time.DateOnly, config.Env.LEGACY_API_DEPRECATION); err == nil {
+				header.Set(HeaderDeprecation, deprecationDate(deprecation))
+			}
+			header.Add(HeaderLink, `<`+utils.APIV1Prefix+strings.TrimPrefix(path, "/api")+`>; rel="successor-version"`)
+			if sunset, err := time.Parse(time.DateOnly, config.Env.LEGACY_API_SUNSET); err == nil {
+				header.Set(HeaderSunset, sunset.Format(http.TimeFormat))
+			}
+			return next(c)
+		}
+	}
+}
+
+// deprecationDate formats a date as the structured field date of the
+// Deprecation header of RFC 9745, @ followed by its unix time
+func deprecationDate(t time.Time) string {
+	return "@" + strconv.FormatInt(t.Unix(), 10)
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeprecationDate(t *testing.T) {
+	date, err := time.Parse(time.DateOnly, "2026-10-19")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := deprecationDate(date), "@1792368000"; got != want {
+		t.Errorf("deprecationDate() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// routeCaches are the seconds shared caches may keep the responses of the
+// routes starting with prefix, the first match wins, the versioned routes are
+// cached as their legacy ones. The stats are kept as long as the response
+// cache keeps them. A nil ttl forbids caching.
+var routeCaches = []struct {
+	prefix string
+	ttl    func(env *config.ReloadableEnv) int
+}{
+	{"/api/admin", nil},
+	{"/api/stats/summary", func(env *config.ReloadableEnv) int { return env.CACHE_SUMMARY_TTL }},
+	{"/api/stats/chart", func(env *config.ReloadableEnv) int { return env.CACHE_CHART_TTL }},
+	{"/api/stats/volume/top-", func(env *config.ReloadableEnv) int { return env.CACHE_LEADERBOARD_TTL }},
+	{"/api/stats/transaction/top-", func(env *config.ReloadableEnv) int { return env.CACHE_LEADERBOARD_TTL }},
+	{"/api/stats/users/", func(env *config.ReloadableEnv) int { return env.CACHE_PROFILE_TTL }},
+	{"/api/stats", func(env *config.ReloadableEnv) int { return env.HTTP_CACHE_S_MAXAGE }},
+	{"/api/x", func(env *config.ReloadableEnv) int { return env.HTTP_CACHE_S_MAXAGE }},
+}
+
+// HTTPCache makes the successful GET responses of the API cacheable: it sets
+// the Cache-Control of their route unless the handler did, tags them with an
+// ETag and answers the requests whose If-None-Match holds it with a 304. The
+// responses of COMPRESS_MIN_LENGTH bytes or more are compressed with brotli or
+// gzip. Responses are buffered to be hashed, so it must not wrap streams.
+func HTTPCache() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if (req.Method != http.MethodGet && req.Method != http.MethodHead) || !strings.HasPrefix(req.URL.Path, "/api/") {
+				return next(c)
+			}
+
+			res := c.Response()
+			buf := &bufferedWriter{ResponseWriter: res.Writer}
+			res.Writer = buf
+			err := next(c)
+			res.Writer = buf.ResponseWriter
+			if !res.Committed {
+				// Nothing was written, errors are sent by the error handler
+				return err
+			}
+
+			header := res.Header()
+			body := buf.body.Bytes()
+			compressible := len(body) >= config.Env.COMPRESS_MIN_LENGTH
+			if compressible {
+				header.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			}
+			if buf.status == http.StatusOK {
+				if header.Get(echo.HeaderCacheControl) == "" {
+					header.Set(echo.HeaderCacheControl, cacheControl(utils.UnversionedPath(c.Path())))
+				}
+				sum := sha256.Sum256(body)
+				etag := fmt.Sprintf(`W/"%x"`, sum[:16])
+				header.Set(HeaderETag, etag)
+				if etagMatch(req.Header.Get(HeaderIfNoneMatch), etag) {
+					header.Del(echo.HeaderContentLength)
+					buf.ResponseWriter.WriteHeader(http.StatusNotModified)
+					return err
+				}
+			}
+
+			if compressible {
+				if encoding := acceptedEncoding(req.Header.Get(echo.HeaderAcceptEncoding)); encoding != "" {
+					if compressed, cerr := compress(encoding, body); cerr != nil {
+						log.Warn().Err(cerr).Str("encoding", encoding).Msg("failed to compress response")
+					} else {
+						header.Set(echo.HeaderContentEncoding, encoding)
+						header.Set(echo.HeaderContentLength, strconv.Itoa(len(compressed)))
+						body = compressed
+					}
+				}
+			}
+
+			buf.ResponseWriter.WriteHeader(buf.status)
+			if _, werr := buf.ResponseWriter.Write(body); werr != nil {
+				log.Debug().Err(werr).Msg("failed to write response")
+			}
+			return err
+		}
+	}
+}
+
+// bufferedWriter holds the response until the handler is done
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// cacheControl is the Cache-Control of the successful responses of path,
+// browsers revalidate them after HTTP_CACHE_MAX_AGE at most
+func cacheControl(path string) string {
+	env := config.Reloadable()
+	for _, rc := range routeCaches {
+		if !strings.HasPrefix(path, rc.prefix) {
+			continue
+		}
+		if rc.ttl == nil {
+			return "no-store"
+		}
+		ttl := rc.ttl(env)
+		return fmt.Sprintf("public, max-age=%d, s-maxage=%d", min(env.HTTP_CACHE_MAX_AGE, ttl), ttl)
+	}
+	return "no-cache"
+}
+
+// etagMatch reports whether the If-None-Match header lists etag, comparing the
+// tags weakly
+func etagMatch(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedEncoding picks brotli over gzip among the encodings the client
+// accepts, it is empty when the client accepts neither
+func acceptedEncoding(acceptEncoding string) string {
+	var gzipOK bool
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "br":
+			return "br"
+		case "gzip":
+			gzipOK = true
+		}
+	}
+	if gzipOK {
+		return "gzip"
+	}
+	return ""
+}
+
+func compress(encoding string, body []byte) ([]byte, error) {
+	var (
+		out bytes.Buffer
+		w   io.WriteCloser
+	)
+	switch encoding {
+	case "br":
+		w = brotli.NewWriterLevel(&out, brotli.DefaultCompression)
+	case "gzip":
+		w = gzip.NewWriter(&out)
+	default:
+		return nil, fmt.Errorf("unsupported encoding %s", encoding)
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
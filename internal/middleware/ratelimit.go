@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/apikey"
+	"github.com/scalarorg/scalar-service/pkg/ratelimit"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+// routeCosts are the tokens taken by the routes starting with prefix, the
+// first match wins, the versioned routes cost the same as their legacy ones.
+// The stats queries scan whole tables so they cost more than the lookups of a
+// single document. Other routes take one token.
+var routeCosts = []struct {
+	prefix string
+	cost   int
+}{
+	{"/api/stats/users/", 2},
+	{"/api/stats/dashboard", 10},
+	{"/api/stats", 5},
+	{"/api/x/export", 20},
+	{"/api/x/batch", 10},
+	{"/api/x/:type/:tx_hash", 1},
+	{"/api/x", 2},
+	{"/graphql", 5},
+}
+
+// RateLimit limits the requests of each client with a token bucket, see
+// RATE_LIMIT_PER_MINUTE and RATE_LIMIT_BURST. A client is its authenticated
+// API key, whose tier scales the limit, or its IP otherwise. It runs after
+// APIKeyAuth, which charges the requests it rejects to their IP. The health
+// checks and metrics are never limited, and requests are let through when
+// the store fails.
+func RateLimit() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			env := config.Reloadable()
+			if ratelimit.Default == nil || env.RATE_LIMIT_PER_MINUTE == 0 || skipRateLimit(c) {
+				return next(c)
+			}
+
+			limit := ratelimit.Limit{Burst: env.RATE_LIMIT_BURST, PerMinute: env.RATE_LIMIT_PER_MINUTE}
+			client := "ip:" + c.RealIP()
+			if key := APIKeyFromContext(c); key != nil {
+				multiplier := apikey.QuotaMultiplier(key.Tier)
+				if multiplier == 0 {
+					return next(c)
+				}
+				limit.Burst *= multiplier
+				limit.PerMinute *= multiplier
+				client = "key:" + strconv.FormatUint(key.ID, 10)
+			}
+
+			if err := takeTokens(c, client, limit); err != nil {
+				return err
+			}
+			return next(c)
+		}
+	}
+}
+
+// chargeIP charges a request rejected before RateLimit to its IP, so that
+// guessing API keys is limited like any anonymous request. It fails when the
+// bucket of the IP is empty.
+func chargeIP(c echo.Context) error {
+	env := config.Reloadable()
+	if ratelimit.Default == nil || env.RATE_LIMIT_PER_MINUTE == 0 || skipRateLimit(c) {
+		return nil
+	}
+	limit := ratelimit.Limit{Burst: env.RATE_LIMIT_BURST, PerMinute: env.RATE_LIMIT_PER_MINUTE}
+	return takeTokens(c, "ip:"+c.RealIP(), limit)
+}
+
+// takeTokens takes the cost of the route from the bucket of client and sets
+// the rate limit headers, it fails with a 429 when the bucket is empty
+func takeTokens(c echo.Context, client string, limit ratelimit.Limit) error {
+	cost := min(routeCost(utils.UnversionedPath(c.Path())), limit.Burst)
+	res, err := ratelimit.Default.Take(c.Request().Context(), client, cost, limit)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to rate limit request")
+		return nil
+	}
+
+	header := c.Response().Header()
+	header.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+	header.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+	header.Set(HeaderRateLimitReset, strconv.Itoa(int(res.Reset.Seconds())))
+	if !res.Allowed {
+		header.Set(echo.HeaderRetryAfter, strconv.Itoa(int(res.RetryAfter.Seconds())))
+		return echo.NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded")
+	}
+	return nil
+}
+
+func skipRateLimit(c echo.Context) bool {
+	path := c.Request().URL.Path
+	return c.Request().Method == http.MethodOptions ||
+		strings.HasPrefix(path, "/health") ||
+		strings.HasPrefix(path, "/metrics")
+}
+
+func routeCost(path string) int {
+	for _, rc := range routeCosts {
+		if strings.HasPrefix(path, rc.prefix) {
+			return rc.cost
+		}
+	}
+	return 1
+}
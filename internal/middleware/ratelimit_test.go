@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+func TestRouteCost(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/api/stats/users/:address", 2},
+		{"/api/stats/dashboard", 10},
+		{"/api/stats/chart/txs", 5},
+		{"/api/stats/volume/top-users", 5},
+		{"/api/x/export", 20},
+		{"/api/x/batch", 10},
+		{"/api/x/:type/:tx_hash", 1},
+		{"/api/x", 2},
+		{"/graphql", 5},
+		{"/api/admin/cache", 1},
+		{"/api/v1/stats/dashboard", 10},
+		{"/api/v1/x/:type/:tx_hash", 1},
+	}
+	for _, tt := range tests {
+		if got := routeCost(utils.UnversionedPath(tt.path)); got != tt.want {
+			t.Errorf("routeCost(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
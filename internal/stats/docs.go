@@ -0,0 +1,41 @@
+package stats
+
+import (
+	"github.com/scalarorg/scalar-service/internal/stats/handlers"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/openapi"
+	"github.com/scalarorg/scalar-service/pkg/types"
+)
+
+var leaderboardHeaders = map[string]string{
+	handlers.HeaderNextCursor: "Cursor of the next page, absent on the last page",
+}
+
+const chartDescription = "One point per time bucket. With group_by, one series per chain, path or asset instead."
+
+var chartResponse = openapi.OneOf{[]services.StatsPayload{}, []services.StatsSeries{}}
+
+func Docs() []openapi.Operation {
+	return []openapi.Operation{
+		{Handler: handlers.GetTopUsersByVolume, Summary: "Users by bridged volume", Params: services.LeaderboardOpts{}, Response: []types.AddressAmount{}, Headers: leaderboardHeaders},
+		{Handler: handlers.GetTopBridgesByVolume, Summary: "Largest bridges", Params: services.LeaderboardOpts{}, Response: []types.AddressAmount{}, Headers: leaderboardHeaders},
+		{Handler: handlers.GetTopSourceChainsByVolume, Summary: "Source chains by volume", Params: services.LeaderboardOpts{}, Response: []types.ChainAmount{}, Headers: leaderboardHeaders},
+		{Handler: handlers.GetTopDestinationChainsByVolume, Summary: "Destination chains by volume", Params: services.LeaderboardOpts{}, Response: []types.ChainAmount{}, Headers: leaderboardHeaders},
+		{Handler: handlers.GetTopPathsByVolume, Summary: "Paths by volume", Params: services.LeaderboardOpts{}, Response: []types.PathAmount{}, Headers: leaderboardHeaders},
+		{Handler: handlers.GetTopSourceChainsByTx, Summary: "Source chains by transactions", Params: services.LeaderboardOpts{}, Response: []types.ChainAmount{}, Headers: leaderboardHeaders},
+		{Handler: handlers.GetTopDestinationChainsByTx, Summary: "Destination chains by transactions", Params: services.LeaderboardOpts{}, Response: []types.ChainAmount{}, Headers: leaderboardHeaders},
+		{Handler: handlers.GetTopPathsByTx, Summary: "Paths by transactions", Params: services.LeaderboardOpts{}, Response: []types.PathAmount{}, Headers: leaderboardHeaders},
+
+		{Handler: handlers.GetTxsStatsHandler, Summary: "Transactions chart", Description: chartDescription, Params: services.StatsOpts{}, Response: chartResponse},
+		{Handler: handlers.GetVolumesStatsHandler, Summary: "Volumes chart", Description: chartDescription, Params: services.StatsOpts{}, Response: chartResponse},
+		{Handler: handlers.GetActiveUsersStatsHandler, Summary: "Active users chart", Description: chartDescription, Params: services.StatsOpts{}, Response: chartResponse},
+		{Handler: handlers.GetNewUsersStatsHandler, Summary: "New users chart", Description: chartDescription, Params: services.StatsOpts{}, Response: chartResponse},
+
+		{Handler: handlers.GetSummaryStatsHandler, Summary: "Protocol totals", Params: services.StatsOpts{}, Response: services.SummaryStats{}},
+		{Handler: handlers.GetDashboardHandler, Summary: "Dashboard sections", Description: "Sections that failed are named in errors, the response is 503 when they all failed.", Params: services.DashboardOpts{}, Response: services.Dashboard{}},
+		{Handler: handlers.GetCohortsHandler, Summary: "User retention cohorts", Params: services.CohortOpts{}, Response: []services.Cohort{}},
+		{Handler: handlers.GetDistributionHandler, Summary: "Distribution of transaction amounts", Params: services.DistributionOpts{}, Response: services.Distribution{}},
+		{Handler: handlers.GetTVLHandler, Summary: "Total value locked", Params: services.TVLOpts{}, Response: services.TVL{}},
+		{Handler: handlers.GetUserProfileHandler, Summary: "User profile", Params: handlers.UserProfileOpts{}, Response: services.UserProfile{}},
+	}
+}
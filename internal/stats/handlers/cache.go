@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/pkg/cache"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// cached serves the result of load from the response cache, keyed by the
+// matched route and the normalized request options
+func cached[T any](c echo.Context, policy cache.Policy, opts any, load func(ctx context.Context) (T, error)) (T, error) {
+	return cache.Remember(c.Request().Context(), cache.Default, cache.Key(utils.UnversionedPath(c.Path()), opts), policy, load)
+}
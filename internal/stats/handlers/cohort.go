@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+func GetCohortsHandler(c echo.Context) error {
+	var opts services.CohortOpts
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+
+	// Default to the last 10 weekly cohorts
+	if opts.TimeBucket == "" {
+		opts.TimeBucket = "week"
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 10
+	}
+	if opts.TZ == "" {
+		opts.TZ = "UTC"
+	}
+
+	cohorts, err := cached(c, services.ChartCachePolicy(), opts, func(ctx context.Context) ([]*services.Cohort, error) {
+		return services.GetCohorts(ctx, &opts)
+	})
+	if err != nil {
+		return err
+	}
+	return utils.JSON(c, http.StatusOK, cohorts)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/constants"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/price"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+func GetDashboardHandler(c echo.Context) error {
+	var opts services.DashboardOpts
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+	if err := opts.ParseFields(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	if err := opts.ParsePath(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if opts.USD && !price.Enabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, constants.ErrPriceDisabled)
+	}
+
+	switch opts.Network {
+	case "mainnet":
+		opts.Network = "bitcoin|1"
+	default:
+		opts.Network = constants.DefaultChain
+	}
+	if opts.TimeBucket == "" {
+		opts.TimeBucket = "day"
+	}
+	setDefaultOpts(&opts.StatsOpts)
+
+	// Only complete dashboards are cached, a partial one is served as is so
+	// the failed sections are retried on the next request
+	dashboard, err := cached(c, services.SummaryCachePolicy(), opts, func(ctx context.Context) (*services.Dashboard, error) {
+		dashboard := services.GetDashboard(ctx, &opts)
+		if len(dashboard.Errors) > 0 {
+			return nil, &services.PartialDashboardError{Dashboard: dashboard}
+		}
+		return dashboard, nil
+	})
+	var partial *services.PartialDashboardError
+	if errors.As(err, &partial) {
+		if len(partial.Dashboard.Data) == 0 {
+			return dashboardJSON(c, http.StatusServiceUnavailable, partial.Dashboard)
+		}
+		return dashboardJSON(c, http.StatusOK, partial.Dashboard)
+	}
+	if err != nil {
+		return err
+	}
+	return dashboardJSON(c, http.StatusOK, dashboard)
+}
+
+// dashboardJSON sends the sections of a dashboard, the versioned API names the
+// failed sections in its errors
+func dashboardJSON(c echo.Context, status int, dashboard *services.Dashboard) error {
+	envelope := &utils.Envelope{Data: dashboard.Data}
+	sections := make([]string, 0, len(dashboard.Errors))
+	for section := range dashboard.Errors {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	for _, section := range sections {
+		envelope.Errors = append(envelope.Errors, &utils.ErrResponse{
+			Message:  dashboard.Errors[section],
+			Metadata: map[string]string{"section": section},
+		})
+	}
+	return utils.Respond(c, status, dashboard, envelope)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+func GetDistributionHandler(c echo.Context) error {
+	var opts services.DistributionOpts
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+	if _, err := services.ParseBuckets(opts.Buckets); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	distribution, err := cached(c, services.ChartCachePolicy(), opts, func(ctx context.Context) (*services.Distribution, error) {
+		return services.GetDistribution(ctx, &opts)
+	})
+	if err != nil {
+		return err
+	}
+	return utils.JSON(c, http.StatusOK, distribution)
+}
@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
-	"strconv"
 
 	"github.com/labstack/echo/v4"
 	"github.com/scalarorg/scalar-service/constants"
 	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/price"
 	"github.com/scalarorg/scalar-service/pkg/utils"
 )
 
@@ -22,29 +24,21 @@ func setDefaultOpts(opts *services.StatsOpts) {
 			opts.Limit = 10
 		}
 	}
-}
-
-func getLimit(c echo.Context) int {
-	limit := c.QueryParam("limit")
-	size := c.QueryParam("size")
-	if limit == "" {
-		if size == "" {
-			limit = constants.DefaultLimit
-		} else {
-			limit = size
-		}
+	// Size is only an alias of Limit, drop it so both spellings share a cache key
+	opts.Size = 0
+	if opts.TZ == "" {
+		opts.TZ = "UTC"
 	}
-	limitInt, err := strconv.Atoi(limit)
-	if err != nil {
-		return 10
-	}
-	return limitInt
 }
 
-func GetTxsStatsHandler(c echo.Context) error {
+// bindChartOpts binds the options of a chart endpoint and sets their defaults
+func bindChartOpts(c echo.Context) (*services.StatsOpts, error) {
 	var opts services.StatsOpts
 	if err := utils.BindAndValidate(c, &opts); err != nil {
-		return err
+		return nil, err
+	}
+	if err := opts.ParsePath(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err)
 	}
 
 	//Set default network to testnet4
@@ -57,83 +51,69 @@ func GetTxsStatsHandler(c echo.Context) error {
 
 	//Set default limit to 10
 	setDefaultOpts(&opts)
-
-	txs, err := services.GetTxsChartData(c.Request().Context(), &opts)
-	if err != nil {
-		return err
-	}
-	return c.JSON(http.StatusOK, txs)
+	return &opts, nil
 }
 
-func GetVolumesStatsHandler(c echo.Context) error {
-	var opts services.StatsOpts
-	if err := utils.BindAndValidate(c, &opts); err != nil {
-		return err
+// chartJSON serves the series of load, or one series per group of the chart
+// when group_by is set
+func chartJSON(c echo.Context, chart string, opts *services.StatsOpts, load func(context.Context, *services.StatsOpts) ([]*services.StatsPayload, error)) error {
+	if opts.GroupBy != "" {
+		series, err := cached(c, services.ChartCachePolicy(), opts, func(ctx context.Context) ([]*services.StatsSeries, error) {
+			return services.GetChartSeries(ctx, chart, opts)
+		})
+		if err != nil {
+			return err
+		}
+		return utils.JSON(c, http.StatusOK, series)
 	}
 
-	//Set default network to testnet4
-	switch opts.Network {
-	case "mainnet":
-		opts.Network = "bitcoin|1"
-	case "testnet":
-		opts.Network = constants.DefaultChain
+	data, err := cached(c, services.ChartCachePolicy(), opts, func(ctx context.Context) ([]*services.StatsPayload, error) {
+		return load(ctx, opts)
+	})
+	if err != nil {
+		return err
 	}
+	return utils.JSON(c, http.StatusOK, data)
+}
 
-	//Set default limit to 10
-	setDefaultOpts(&opts)
-
-	volumes, err := services.GetVolumesStats(c.Request().Context(), &opts)
+func GetTxsStatsHandler(c echo.Context) error {
+	opts, err := bindChartOpts(c)
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, volumes)
+	return chartJSON(c, db.ChartTxs, opts, services.GetTxsChartData)
 }
 
-func GetActiveUsersStatsHandler(c echo.Context) error {
-	var opts services.StatsOpts
-	if err := utils.BindAndValidate(c, &opts); err != nil {
+func GetVolumesStatsHandler(c echo.Context) error {
+	opts, err := bindChartOpts(c)
+	if err != nil {
 		return err
 	}
 
-	//Set default network to testnet4
-	switch opts.Network {
-	case "mainnet":
-		opts.Network = "bitcoin|1"
-	case "testnet":
-		opts.Network = constants.DefaultChain
+	if opts.USD && !price.Enabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, constants.ErrPriceDisabled)
+	}
+	if opts.USD && opts.DrillDown() {
+		return echo.NewHTTPError(http.StatusBadRequest, services.ErrUSDDrillDown)
 	}
 
-	//Set default limit to 10
-	setDefaultOpts(&opts)
+	return chartJSON(c, db.ChartVolumes, opts, services.GetVolumesStats)
+}
 
-	activeUsers, err := services.GetActiveUsersStats(c.Request().Context(), &opts)
+func GetActiveUsersStatsHandler(c echo.Context) error {
+	opts, err := bindChartOpts(c)
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, activeUsers)
+	return chartJSON(c, db.ChartActiveUsers, opts, services.GetActiveUsersStats)
 }
 
 func GetNewUsersStatsHandler(c echo.Context) error {
-	var opts services.StatsOpts
-	if err := utils.BindAndValidate(c, &opts); err != nil {
-		return err
-	}
-
-	//Set default network to testnet4
-	switch opts.Network {
-	case "mainnet":
-		opts.Network = "bitcoin|1"
-	case "testnet":
-		opts.Network = constants.DefaultChain
-	}
-	//Set default limit to 10
-	setDefaultOpts(&opts)
-
-	newUsers, err := services.GetNewUsersStats(c.Request().Context(), &opts)
+	opts, err := bindChartOpts(c)
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, newUsers)
+	return chartJSON(c, db.ChartNewUsers, opts, services.GetNewUsersStats)
 }
 
 func GetSummaryStatsHandler(c echo.Context) error {
@@ -142,6 +122,10 @@ func GetSummaryStatsHandler(c echo.Context) error {
 		return err
 	}
 
+	if opts.USD && !price.Enabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, constants.ErrPriceDisabled)
+	}
+
 	switch opts.Network {
 	case "mainnet":
 		opts.Network = "bitcoin|1"
@@ -151,9 +135,11 @@ func GetSummaryStatsHandler(c echo.Context) error {
 		opts.Network = constants.DefaultChain
 	}
 
-	summary, err := services.GetSummaryStats(c.Request().Context(), &opts)
+	summary, err := cached(c, services.SummaryCachePolicy(), services.SummaryCacheKey(&opts), func(ctx context.Context) (*services.SummaryStats, error) {
+		return services.GetSummaryStats(ctx, &opts)
+	})
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, summary)
+	return utils.JSON(c, http.StatusOK, summary)
 }
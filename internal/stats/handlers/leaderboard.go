@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// HeaderNextCursor carries the cursor of the next leaderboard page, it is
+// absent on the last page
+const HeaderNextCursor = "X-Next-Cursor"
+
+func bindLeaderboardOpts(c echo.Context) (*services.LeaderboardOpts, error) {
+	var opts services.LeaderboardOpts
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return nil, err
+	}
+	if err := opts.Normalize(); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	return &opts, nil
+}
+
+// leaderboardJSON sends a page of a leaderboard, the cursor of the next page is
+// also in the meta of the versioned API
+func leaderboardJSON[T any](c echo.Context, opts *services.LeaderboardOpts, rows []T) error {
+	cursor := opts.NextCursor(len(rows))
+	if cursor != "" {
+		c.Response().Header().Set(HeaderNextCursor, cursor)
+	}
+	return utils.Respond(c, http.StatusOK, rows, &utils.Envelope{
+		Data: rows,
+		Meta: utils.Meta{Size: &opts.Limit, NextCursor: cursor},
+	})
+}
@@ -1,39 +1,57 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/labstack/echo/v4"
 	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/types"
 )
 
 func GetTopSourceChainsByTx(c echo.Context) error {
-	limitInt := getLimit(c)
+	opts, err := bindLeaderboardOpts(c)
+	if err != nil {
+		return err
+	}
 
-	result, err := services.StatTransactionBySourceChain(limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.ChainAmount, error) {
+		return services.StatTransactionBySourceChain(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
 
 func GetTopDestinationChainsByTx(c echo.Context) error {
-	limitInt := getLimit(c)
+	opts, err := bindLeaderboardOpts(c)
+	if err != nil {
+		return err
+	}
 
-	result, err := services.StatTransactionByDestinationChain(limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.ChainAmount, error) {
+		return services.StatTransactionByDestinationChain(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
 
 func GetTopPathsByTx(c echo.Context) error {
-	limitInt := getLimit(c)
+	opts, err := bindLeaderboardOpts(c)
+	if err != nil {
+		return err
+	}
 
-	result, err := services.StatTransactionByPath(limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.PathAmount, error) {
+		return services.StatTransactionByPath(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
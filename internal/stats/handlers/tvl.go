@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/constants"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+func GetTVLHandler(c echo.Context) error {
+	var opts services.TVLOpts
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+
+	switch opts.Network {
+	case "mainnet":
+		opts.Network = "bitcoin|1"
+	case "testnet":
+		opts.Network = constants.DefaultChain
+	}
+	if opts.TimeBucket == "" {
+		opts.TimeBucket = "day"
+	}
+	if opts.Limit == 0 {
+		opts.Limit = 30
+	}
+	if opts.TZ == "" {
+		opts.TZ = "UTC"
+	}
+
+	tvl, err := cached(c, services.ChartCachePolicy(), opts, func(ctx context.Context) (*services.TVL, error) {
+		return services.GetTVL(ctx, &opts)
+	})
+	if err != nil {
+		return err
+	}
+	return utils.JSON(c, http.StatusOK, tvl)
+}
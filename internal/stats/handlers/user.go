@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/stats/services"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+type UserProfileOpts struct {
+	Address string `param:"address" validate:"required"`
+}
+
+func GetUserProfileHandler(c echo.Context) error {
+	var opts UserProfileOpts
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+
+	address, err := services.NormalizeUserAddress(opts.Address)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	profile, err := cached(c, services.ProfileCachePolicy(), address, func(ctx context.Context) (*services.UserProfile, error) {
+		return services.GetUserProfile(ctx, address)
+	})
+	if err != nil {
+		return err
+	}
+	if profile == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Resource not found")
+	}
+	return utils.JSON(c, http.StatusOK, profile)
+}
@@ -1,75 +1,93 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/labstack/echo/v4"
 	"github.com/scalarorg/scalar-service/constants"
 	"github.com/scalarorg/scalar-service/internal/stats/services"
-	"github.com/scalarorg/scalar-service/pkg/utils"
+	"github.com/scalarorg/scalar-service/pkg/types"
 )
 
 func GetTopUsersByVolume(c echo.Context) error {
-	//ctx := c.Request().Context()
-
-	var body services.StatsOpts
-	err := utils.BindAndValidate(c, &body)
+	opts, err := bindLeaderboardOpts(c)
 	if err != nil {
 		return err
 	}
 
-	limitInt := getLimit(c)
-
-	result, err := services.GetTopUsersByVolume(limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]types.AddressAmount, error) {
+		return services.GetTopUsersByVolume(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
 
 func GetTopBridgesByVolume(c echo.Context) error {
-	limitInt := getLimit(c)
-	chain := c.QueryParam("chain")
-	if chain == "" {
-		chain = constants.DefaultChain
+	opts, err := bindLeaderboardOpts(c)
+	if err != nil {
+		return err
+	}
+	if opts.Chain == "" {
+		opts.Chain = constants.DefaultChain
 	}
 
-	result, err := services.GetTopBridgesByVolume(chain, limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.AddressAmount, error) {
+		return services.GetTopBridgesByVolume(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
 
 func GetTopSourceChainsByVolume(c echo.Context) error {
-	limitInt := getLimit(c)
+	opts, err := bindLeaderboardOpts(c)
+	if err != nil {
+		return err
+	}
 
-	result, err := services.GetTopSourceChainsByVolume(limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.ChainAmount, error) {
+		return services.GetTopSourceChainsByVolume(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
 
 func GetTopDestinationChainsByVolume(c echo.Context) error {
-	limitInt := getLimit(c)
+	opts, err := bindLeaderboardOpts(c)
+	if err != nil {
+		return err
+	}
 
-	result, err := services.GetTopDestinationChainsByVolume(limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.ChainAmount, error) {
+		return services.GetTopDestinationChainsByVolume(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
 
 func GetTopPathsByVolume(c echo.Context) error {
-	limitInt := getLimit(c)
+	opts, err := bindLeaderboardOpts(c)
+	if err != nil {
+		return err
+	}
 
-	result, err := services.GetTopPathsByVolume(limitInt)
+	result, err := cached(c, services.LeaderboardCachePolicy(), opts, func(ctx context.Context) ([]*types.PathAmount, error) {
+		return services.GetTopPathsByVolume(ctx, opts)
+	})
 	if err != nil {
 		return err
 	}
 
-	return c.JSON(200, result)
+	return leaderboardJSON(c, opts, result)
 }
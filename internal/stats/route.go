@@ -26,4 +26,9 @@ func Route(g *echo.Group, path string) {
 	chart.GET("/new-users", handlers.GetNewUsersStatsHandler)
 
 	x.GET("/summary", handlers.GetSummaryStatsHandler)
+	x.GET("/dashboard", handlers.GetDashboardHandler)
+	x.GET("/cohorts", handlers.GetCohortsHandler)
+	x.GET("/distribution", handlers.GetDistributionHandler)
+	x.GET("/tvl", handlers.GetTVLHandler)
+	x.GET("/users/:address", handlers.GetUserProfileHandler)
 }
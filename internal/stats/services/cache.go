@@ -0,0 +1,41 @@
+package services
+
+import (
+	"time"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/cache"
+)
+
+// The cache policies are shared by the REST handlers and the GraphQL resolvers
+// so that both serve the same entries. The TTLs are reloadable settings, so the
+// policies are read on every request.
+
+func SummaryCachePolicy() cache.Policy {
+	return cache.Policy{TTL: seconds(config.Reloadable().CACHE_SUMMARY_TTL), Stale: 5 * time.Minute}
+}
+
+func ChartCachePolicy() cache.Policy {
+	return cache.Policy{TTL: seconds(config.Reloadable().CACHE_CHART_TTL), Stale: 10 * time.Minute}
+}
+
+func LeaderboardCachePolicy() cache.Policy {
+	return cache.Policy{TTL: seconds(config.Reloadable().CACHE_LEADERBOARD_TTL), Stale: 30 * time.Minute}
+}
+
+func ProfileCachePolicy() cache.Policy {
+	return cache.Policy{TTL: seconds(config.Reloadable().CACHE_PROFILE_TTL), Stale: 5 * time.Minute}
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+// SummaryCacheKey is the part of the options a summary depends on
+func SummaryCacheKey(opts *StatsOpts) any {
+	return struct {
+		Network string
+		USD     bool
+		Window  string
+	}{opts.Network, opts.USD, opts.Window}
+}
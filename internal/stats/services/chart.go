@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+var (
+	ErrInvalidPath   = errors.New("path must be a source and a destination chain separated by a colon")
+	ErrPathAndChains = errors.New("path cannot be combined with source_chain or destination_chain")
+	// USD values are apportioned from the global daily volumes
+	ErrUSDDrillDown = errors.New("usd is not available with source_chain, destination_chain, path or group_by")
+)
+
+// StatsSeries is one series of a grouped chart, only the fields of the group
+// are set. Total is the sum of its buckets.
+type StatsSeries struct {
+	SourceChain      string          `json:"source_chain,omitempty"`
+	DestinationChain string          `json:"destination_chain,omitempty"`
+	Asset            string          `json:"asset,omitempty"`
+	Total            uint64          `json:"total"`
+	Data             []*StatsPayload `json:"data"`
+}
+
+// ParsePath splits the path option into the source and destination chains
+func (o *StatsOpts) ParsePath() error {
+	if o.Path == "" {
+		return nil
+	}
+	if o.SourceChain != "" || o.DestinationChain != "" {
+		return ErrPathAndChains
+	}
+	source, destination, ok := strings.Cut(o.Path, ":")
+	if !ok || source == "" || destination == "" {
+		return ErrInvalidPath
+	}
+	o.SourceChain, o.DestinationChain, o.Path = source, destination, ""
+	return nil
+}
+
+// DrillDown tells whether the chart is filtered or grouped, the global charts
+// keep their own queries
+func (o *StatsOpts) DrillDown() bool {
+	return o.SourceChain != "" || o.DestinationChain != "" || o.GroupBy != ""
+}
+
+// GetChartSeries returns one series per group of opts.GroupBy, largest total
+// first. Every series covers the same last opts.Limit buckets and skips the
+// buckets it has no transaction in.
+func GetChartSeries(ctx context.Context, chart string, opts *StatsOpts) ([]*StatsSeries, error) {
+	loc := opts.location()
+	filter := db.ChartFilter{
+		SourceChain:      opts.SourceChain,
+		DestinationChain: opts.DestinationChain,
+		GroupBy:          opts.GroupBy,
+	}
+	points, err := db.GetChartPoints(ctx, chart, opts.TimeBucket, loc.String(), opts.Limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	return chartSeries(points, loc), nil
+}
+
+// chartSeries groups the points of a chart into series, largest total first
+func chartSeries(points []db.ChartPoint, loc *time.Location) []*StatsSeries {
+	type seriesKey struct{ source, destination, asset string }
+	series := make([]*StatsSeries, 0)
+	byKey := make(map[seriesKey]*StatsSeries)
+	for _, p := range points {
+		key := seriesKey{p.SourceChain, p.DestinationChain, p.Asset}
+		s, ok := byKey[key]
+		if !ok {
+			s = &StatsSeries{
+				SourceChain:      p.SourceChain,
+				DestinationChain: p.DestinationChain,
+				Asset:            p.Asset,
+				Data:             make([]*StatsPayload, 0),
+			}
+			byKey[key] = s
+			series = append(series, s)
+		}
+		s.Total += p.Value
+		s.Data = append(s.Data, newStatsPayload(p.Value, p.BucketTime, loc))
+	}
+
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].Total > series[j].Total
+	})
+	return series
+}
+
+// getChartData is the single filtered series of a chart
+func getChartData(ctx context.Context, chart string, opts *StatsOpts) ([]*StatsPayload, error) {
+	single := *opts
+	single.GroupBy = ""
+	series, err := GetChartSeries(ctx, chart, &single)
+	if err != nil {
+		return nil, err
+	}
+	if len(series) == 0 {
+		return make([]*StatsPayload, 0), nil
+	}
+	return series[0].Data, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            StatsOpts
+		wantSource      string
+		wantDestination string
+		wantErr         error
+	}{
+		{name: "no path", opts: StatsOpts{SourceChain: "bitcoin|4"}, wantSource: "bitcoin|4"},
+		{name: "path", opts: StatsOpts{Path: "bitcoin|4:evm|11155111"}, wantSource: "bitcoin|4", wantDestination: "evm|11155111"},
+		{name: "bare chain id", opts: StatsOpts{Path: "11155111:bitcoin|4"}, wantSource: "11155111", wantDestination: "bitcoin|4"},
+		{name: "missing destination", opts: StatsOpts{Path: "bitcoin|4:"}, wantErr: ErrInvalidPath},
+		{name: "missing colon", opts: StatsOpts{Path: "bitcoin|4"}, wantErr: ErrInvalidPath},
+		{name: "with a chain", opts: StatsOpts{Path: "bitcoin|4:evm|1", DestinationChain: "evm|1"}, wantErr: ErrPathAndChains},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := opts.ParsePath()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParsePath() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if opts.SourceChain != tt.wantSource || opts.DestinationChain != tt.wantDestination || opts.Path != "" {
+				t.Errorf("ParsePath() = (%q, %q, path %q), want (%q, %q, path \"\")",
+					opts.SourceChain, opts.DestinationChain, opts.Path, tt.wantSource, tt.wantDestination)
+			}
+		})
+	}
+}
+
+func TestChartSeries(t *testing.T) {
+	day1 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	points := []db.ChartPoint{
+		{BucketTime: day1, SourceChain: "bitcoin|4", DestinationChain: "evm|1", Value: 1},
+		{BucketTime: day1, SourceChain: "evm|1", DestinationChain: "bitcoin|4", Value: 5},
+		{BucketTime: day2, SourceChain: "bitcoin|4", DestinationChain: "evm|1", Value: 2},
+		{BucketTime: day2, SourceChain: "bitcoin|4", DestinationChain: "evm|2", Value: 1},
+	}
+
+	series := chartSeries(points, time.UTC)
+
+	type want struct {
+		source, destination string
+		total               uint64
+		times               []int64
+	}
+	wants := []want{
+		{"evm|1", "bitcoin|4", 5, []int64{day1.Unix()}},
+		{"bitcoin|4", "evm|1", 3, []int64{day1.Unix(), day2.Unix()}},
+		{"bitcoin|4", "evm|2", 1, []int64{day2.Unix()}},
+	}
+	if len(series) != len(wants) {
+		t.Fatalf("chartSeries() returned %d series, want %d", len(series), len(wants))
+	}
+	for i, w := range wants {
+		s := series[i]
+		if s.SourceChain != w.source || s.DestinationChain != w.destination || s.Total != w.total {
+			t.Errorf("series %d = (%s, %s, %d), want (%s, %s, %d)", i, s.SourceChain, s.DestinationChain, s.Total, w.source, w.destination, w.total)
+		}
+		if len(s.Data) != len(w.times) {
+			t.Errorf("series %d has %d buckets, want %d", i, len(s.Data), len(w.times))
+			continue
+		}
+		for j, p := range s.Data {
+			if p.Time != w.times[j] {
+				t.Errorf("series %d bucket %d time = %d, want %d", i, j, p.Time, w.times[j])
+			}
+		}
+	}
+
+	if series := chartSeries(nil, time.UTC); series == nil || len(series) != 0 {
+		t.Errorf("chartSeries(nil) = %v, want an empty slice", series)
+	}
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+type CohortOpts struct {
+	TimeBucket string `query:"time_bucket" validate:"omitempty,oneof=hour day week month"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	// IANA time zone the buckets are cut in, UTC by default
+	TZ string `query:"tz" validate:"omitempty,timezone"`
+}
+
+// Cohort is the retention of the users first seen in one bucket. Retention[i]
+// is how many of them were active i buckets later, so Retention[0] is Size.
+type Cohort struct {
+	Time          int64     `json:"time"`
+	Bucket        string    `json:"bucket"`
+	Size          uint64    `json:"size"`
+	Retention     []uint64  `json:"retention"`
+	RetentionRate []float64 `json:"retention_rate"`
+}
+
+func GetCohorts(ctx context.Context, opts *CohortOpts) ([]*Cohort, error) {
+	loc := location(opts.TZ)
+	activity, err := db.GetCohortActivity(ctx, opts.TimeBucket, loc.String(), opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	cohorts := make([]*Cohort, 0)
+	byTime := make(map[int64]*Cohort)
+	for _, a := range activity {
+		cohort, ok := byTime[a.CohortTime.Unix()]
+		if !ok {
+			cohort = &Cohort{
+				Time:      a.CohortTime.Unix(),
+				Bucket:    a.CohortTime.In(loc).Format(time.RFC3339),
+				Retention: make([]uint64, 0),
+			}
+			byTime[cohort.Time] = cohort
+			cohorts = append(cohorts, cohort)
+		}
+
+		period := bucketsBetween(opts.TimeBucket, a.CohortTime.In(loc), a.BucketTime.In(loc))
+		for len(cohort.Retention) <= period {
+			cohort.Retention = append(cohort.Retention, 0)
+		}
+		cohort.Retention[period] = a.Users
+	}
+
+	for _, cohort := range cohorts {
+		if len(cohort.Retention) > 0 {
+			cohort.Size = cohort.Retention[0]
+		}
+		cohort.RetentionRate = make([]float64, len(cohort.Retention))
+		for i, users := range cohort.Retention {
+			if cohort.Size > 0 {
+				cohort.RetentionRate[i] = float64(users) / float64(cohort.Size)
+			}
+		}
+	}
+	return cohorts, nil
+}
+
+// bucketsBetween counts whole time buckets from start to end. Days and weeks
+// are counted on the calendar, a local day lasts 23 or 25 hours across a DST
+// change.
+func bucketsBetween(timeBucket string, start, end time.Time) int {
+	switch timeBucket {
+	case "month":
+		return (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	case "week":
+		return daysBetween(start, end) / 7
+	case "hour":
+		return int(end.Sub(start).Round(time.Minute) / time.Hour)
+	default:
+		return daysBetween(start, end)
+	}
+}
+
+// daysBetween counts the calendar days from the date of start to that of end
+func daysBetween(start, end time.Time) int {
+	startDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	return int(endDate.Sub(startDate) / (24 * time.Hour))
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketsBetween(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(value string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", value, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	// Clocks spring forward on 2025-03-09 in New York
+	tests := []struct {
+		timeBucket string
+		start, end string
+		want       int
+	}{
+		{"day", "2025-03-08 00:00", "2025-03-08 00:00", 0},
+		{"day", "2025-03-08 00:00", "2025-03-09 00:00", 1},
+		{"day", "2025-03-09 00:00", "2025-03-10 00:00", 1},
+		{"day", "2025-03-08 00:00", "2025-03-11 00:00", 3},
+		{"day", "2025-11-02 00:00", "2025-11-03 00:00", 1},
+		{"week", "2025-03-03 00:00", "2025-03-10 00:00", 1},
+		{"week", "2025-03-03 00:00", "2025-03-17 00:00", 2},
+		{"month", "2025-02-01 00:00", "2025-04-01 00:00", 2},
+		{"hour", "2025-03-09 01:00", "2025-03-09 03:00", 1},
+	}
+	for _, tt := range tests {
+		if got := bucketsBetween(tt.timeBucket, at(tt.start), at(tt.end)); got != tt.want {
+			t.Errorf("bucketsBetween(%s, %s, %s) = %d, want %d", tt.timeBucket, tt.start, tt.end, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+// dashboardTimeout is the deadline shared by every section of the dashboard
+const dashboardTimeout = 20 * time.Second
+
+var ErrUnknownField = errors.New("unknown dashboard field")
+
+type DashboardOpts struct {
+	StatsOpts
+	// Comma separated sections to compute, all of them when empty
+	Fields string `query:"fields"`
+}
+
+// Dashboard holds the sections that loaded, keyed by name. A section that
+// failed is absent from Data and named in Errors, so a zero in Data is always
+// a real zero.
+type Dashboard struct {
+	Data   map[string]any    `json:"data"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// PartialDashboardError is returned alongside a dashboard missing some
+// sections, so that it is served but not cached
+type PartialDashboardError struct {
+	Dashboard *Dashboard
+}
+
+func (e *PartialDashboardError) Error() string {
+	sections := make([]string, 0, len(e.Dashboard.Errors))
+	for name := range e.Dashboard.Errors {
+		sections = append(sections, name)
+	}
+	sort.Strings(sections)
+	return fmt.Sprintf("dashboard sections failed: %s", strings.Join(sections, ", "))
+}
+
+type dashboardSection func(ctx context.Context, opts *DashboardOpts) (any, error)
+
+var dashboardSections = map[string]dashboardSection{
+	"total_txs": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return db.GetTotalTxs(ctx)
+	},
+	"total_volumes": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return db.GetTotalBridgedVolumes(ctx, opts.Network)
+	},
+	"total_users": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return db.GetTotalUsers(ctx)
+	},
+	"txs": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetTxsChartData(ctx, &opts.StatsOpts)
+	},
+	"volumes": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetVolumesStats(ctx, &opts.StatsOpts)
+	},
+	"active_users": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetActiveUsersStats(ctx, &opts.StatsOpts)
+	},
+	"new_users": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetNewUsersStats(ctx, &opts.StatsOpts)
+	},
+	"top_users": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetTopUsersByVolume(ctx, opts.leaderboard())
+	},
+	"top_bridges": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		leaderboard := opts.leaderboard()
+		leaderboard.Chain = opts.Network
+		return GetTopBridgesByVolume(ctx, leaderboard)
+	},
+	"top_source_chains_by_volume": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetTopSourceChainsByVolume(ctx, opts.leaderboard())
+	},
+	"top_destination_chains_by_volume": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetTopDestinationChainsByVolume(ctx, opts.leaderboard())
+	},
+	"top_paths_by_volume": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return GetTopPathsByVolume(ctx, opts.leaderboard())
+	},
+	"top_source_chains_by_tx": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return StatTransactionBySourceChain(ctx, opts.leaderboard())
+	},
+	"top_destination_chains_by_tx": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return StatTransactionByDestinationChain(ctx, opts.leaderboard())
+	},
+	"top_paths_by_tx": func(ctx context.Context, opts *DashboardOpts) (any, error) {
+		return StatTransactionByPath(ctx, opts.leaderboard())
+	},
+}
+
+func (o *DashboardOpts) leaderboard() *LeaderboardOpts {
+	return &LeaderboardOpts{Limit: o.Limit}
+}
+
+// ParseFields validates the fields option and sorts it, so that equivalent
+// selections share a cache key
+func (o *DashboardOpts) ParseFields() error {
+	if o.Fields == "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	fields := make([]string, 0)
+	for _, field := range strings.Split(o.Fields, ",") {
+		field = strings.TrimSpace(field)
+		if _, ok := dashboardSections[field]; !ok {
+			return fmt.Errorf("%w %q", ErrUnknownField, field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	o.Fields = strings.Join(fields, ",")
+	return nil
+}
+
+// GetDashboard computes the selected sections concurrently. Sections still
+// running when the deadline passes are reported as failed.
+func GetDashboard(ctx context.Context, opts *DashboardOpts) *Dashboard {
+	ctx, cancel := context.WithTimeout(ctx, dashboardTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(dashboardSections))
+	if opts.Fields != "" {
+		names = strings.Split(opts.Fields, ",")
+	} else {
+		for name := range dashboardSections {
+			names = append(names, name)
+		}
+	}
+
+	type result struct {
+		name  string
+		value any
+		err   error
+	}
+	// Buffered so that sections finishing after the deadline don't block
+	results := make(chan result, len(names))
+	for _, name := range names {
+		section := dashboardSections[name]
+		go func() {
+			value, err := section(ctx, opts)
+			results <- result{name, value, err}
+		}()
+	}
+
+	dashboard := &Dashboard{Data: make(map[string]any, len(names))}
+	pending := make(map[string]bool, len(names))
+	for _, name := range names {
+		pending[name] = true
+	}
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				log.Error().Err(r.err).Str("section", r.name).Msg("failed to get dashboard section")
+				dashboard.fail(r.name, r.err)
+				continue
+			}
+			dashboard.Data[r.name] = r.value
+		case <-ctx.Done():
+			for name := range pending {
+				dashboard.fail(name, ctx.Err())
+			}
+			return dashboard
+		}
+	}
+	return dashboard
+}
+
+func (d *Dashboard) fail(name string, err error) {
+	if d.Errors == nil {
+		d.Errors = make(map[string]string)
+	}
+	d.Errors[name] = err.Error()
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+const maxDistributionBuckets = 50
+
+var ErrInvalidBuckets = errors.New("buckets must be ascending positive integers")
+
+// DistributionOpts selects the amounts of a distribution. Buckets is a comma
+// separated list of ascending edges, by default buckets are powers of ten.
+// Amounts are in the smallest unit of their asset, so mixing assets only makes
+// sense when they share decimals.
+type DistributionOpts struct {
+	Type    string `query:"type" validate:"omitempty,oneof=bridge transfer redeem"`
+	Chain   string `query:"chain"`
+	Asset   string `query:"asset"`
+	Buckets string `query:"buckets"`
+}
+
+// DistributionBucket counts the amounts in [Min, Max), Max is null for the
+// last bucket of custom edges
+type DistributionBucket struct {
+	Min    uint64  `json:"min"`
+	Max    *uint64 `json:"max"`
+	Count  uint64  `json:"count"`
+	Amount uint64  `json:"amount"`
+	Share  float64 `json:"share"`
+}
+
+type Percentiles struct {
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+type Distribution struct {
+	Count       uint64                `json:"count"`
+	Min         uint64                `json:"min"`
+	Max         uint64                `json:"max"`
+	Mean        float64               `json:"mean"`
+	Median      float64               `json:"median"`
+	Percentiles Percentiles           `json:"percentiles"`
+	Buckets     []*DistributionBucket `json:"buckets"`
+}
+
+// ParseBuckets parses the buckets option into ascending edges
+func ParseBuckets(buckets string) ([]uint64, error) {
+	if buckets == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(buckets, ",")
+	if len(parts) > maxDistributionBuckets {
+		return nil, ErrInvalidBuckets
+	}
+	edges := make([]uint64, 0, len(parts))
+	for i, part := range parts {
+		edge, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil || edge == 0 || (i > 0 && edge <= edges[i-1]) {
+			return nil, ErrInvalidBuckets
+		}
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+// GetDistribution computes the histogram and the percentiles in parallel
+func GetDistribution(ctx context.Context, opts *DistributionOpts) (*Distribution, error) {
+	edges, err := ParseBuckets(opts.Buckets)
+	if err != nil {
+		return nil, err
+	}
+	filter := db.DistributionFilter{
+		Kind:  db.CrossChainTx(opts.Type),
+		Chain: opts.Chain,
+		Asset: opts.Asset,
+		Edges: edges,
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	var histogram []db.AmountBucket
+	var percentiles *db.AmountPercentiles
+	var histogramErr, percentilesErr error
+	go func() {
+		defer wg.Done()
+		histogram, histogramErr = db.GetAmountHistogram(ctx, filter)
+	}()
+	go func() {
+		defer wg.Done()
+		percentiles, percentilesErr = db.GetAmountPercentiles(ctx, filter)
+	}()
+	wg.Wait()
+
+	if histogramErr != nil {
+		return nil, histogramErr
+	}
+	if percentilesErr != nil {
+		return nil, percentilesErr
+	}
+
+	var buckets []*DistributionBucket
+	if len(edges) > 0 {
+		buckets = edgeBuckets(edges, histogram)
+	} else {
+		buckets = logBuckets(histogram)
+	}
+	for _, bucket := range buckets {
+		if percentiles.Count > 0 {
+			bucket.Share = float64(bucket.Count) / float64(percentiles.Count)
+		}
+	}
+
+	return &Distribution{
+		Count:  percentiles.Count,
+		Min:    percentiles.Min,
+		Max:    percentiles.Max,
+		Mean:   percentiles.Mean,
+		Median: percentiles.P50,
+		Percentiles: Percentiles{
+			P25: percentiles.P25,
+			P50: percentiles.P50,
+			P75: percentiles.P75,
+			P90: percentiles.P90,
+			P95: percentiles.P95,
+			P99: percentiles.P99,
+		},
+		Buckets: buckets,
+	}, nil
+}
+
+// edgeBuckets lays out every bucket of the edges, including empty ones
+func edgeBuckets(edges []uint64, histogram []db.AmountBucket) []*DistributionBucket {
+	buckets := make([]*DistributionBucket, len(edges)+1)
+	for i := range buckets {
+		bucket := &DistributionBucket{}
+		if i > 0 {
+			bucket.Min = edges[i-1]
+		}
+		if i < len(edges) {
+			upper := edges[i]
+			bucket.Max = &upper
+		}
+		buckets[i] = bucket
+	}
+	for _, h := range histogram {
+		if h.Bucket >= 0 && h.Bucket < len(buckets) {
+			buckets[h.Bucket].Count = h.Count
+			buckets[h.Bucket].Amount = h.Amount
+		}
+	}
+	return buckets
+}
+
+// logBuckets lays out the powers of ten from the smallest to the largest
+// amount, filling the gaps with empty buckets
+func logBuckets(histogram []db.AmountBucket) []*DistributionBucket {
+	buckets := make([]*DistributionBucket, 0)
+	if len(histogram) == 0 {
+		return buckets
+	}
+
+	byExponent := make(map[int]db.AmountBucket, len(histogram))
+	for _, h := range histogram {
+		byExponent[h.Bucket] = h
+	}
+	first, last := histogram[0].Bucket, histogram[len(histogram)-1].Bucket
+	for exp := first; exp <= last; exp++ {
+		bucket := &DistributionBucket{
+			Min:    uint64(math.Pow10(exp)),
+			Count:  byExponent[exp].Count,
+			Amount: byExponent[exp].Amount,
+		}
+		// 10^20 overflows uint64, the last power of ten is left open
+		if exp < 19 {
+			upper := uint64(math.Pow10(exp + 1))
+			bucket.Max = &upper
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
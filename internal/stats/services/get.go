@@ -2,11 +2,12 @@ package services
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/scalarorg/scalar-service/pkg/db"
-	"github.com/scalarorg/scalar-service/pkg/types"
 )
 
 type StatsOpts struct {
@@ -14,199 +15,109 @@ type StatsOpts struct {
 	Size       int    `query:"size" validate:"omitempty,min=1,max=100"`
 	Network    string `query:"network" validate:"omitempty,oneof=mainnet testnet"`
 	TimeBucket string `query:"time_bucket" validate:"omitempty,oneof=hour day week month"`
+	USD        bool   `query:"usd"`
+	Window     string `query:"window" validate:"omitempty,oneof=24h 7d 30d"`
+	// IANA time zone the buckets are cut in, UTC by default
+	TZ string `query:"tz" validate:"omitempty,timezone"`
+
+	// Chart drill-down, Path is a source and a destination chain separated by
+	// a colon and is split into both by ParsePath
+	SourceChain      string `query:"source_chain"`
+	DestinationChain string `query:"destination_chain"`
+	Path             string `query:"path"`
+	GroupBy          string `query:"group_by" validate:"omitempty,oneof=chain path asset"`
 }
 
-type StatsPayload struct {
-	Value uint64 `json:"data"`
-	Time  int64  `json:"time"`
-}
-
-type StatsResponse struct {
-	TotalTxs                     int64                  `json:"total_txs"`
-	TotalVolumes                 int64                  `json:"total_volumes"`
-	TotalUsers                   int64                  `json:"total_users"`
-	Txs                          []*StatsPayload        `json:"txs"`
-	Volumes                      []*StatsPayload        `json:"volumes"`
-	ActiveUsers                  []*StatsPayload        `json:"active_users"`
-	NewUsers                     []*StatsPayload        `json:"new_users"`
-	TopUsers                     []types.AddressAmount  `json:"top_users"`
-	TopBridges                   []*types.AddressAmount `json:"top_bridges"`
-	TopSourceChainsByVolume      []*types.ChainAmount   `json:"top_source_chains_by_volume"`
-	TopDestinationChainsByVolume []*types.ChainAmount   `json:"top_destination_chains_by_volume"`
-	TopPathsByVolume             []*types.PathAmount    `json:"top_paths_by_volume"`
-	TopSourceChainsByTx          []*types.ChainAmount   `json:"top_source_chains_by_tx"`
-	TopDestinationChainsByTx     []*types.ChainAmount   `json:"top_destination_chains_by_tx"`
-	TopPathsByTx                 []*types.PathAmount    `json:"top_paths_by_tx"`
+func (o *StatsOpts) location() *time.Location {
+	return location(o.TZ)
 }
 
-// Todo: Consider using graphql for seperate stat request
-func GetStats(ctx context.Context, opts *StatsOpts) (*StatsResponse, error) {
-	cmds, err := db.GetCommandStats(ctx, opts.TimeBucket, opts.Limit)
-	if err != nil {
-		return nil, err
-	}
-	response := &StatsResponse{}
-	tokenSentSats, err := db.GetTokenStats(opts.TimeBucket, opts.Limit)
+// location is the time zone named tz, the option is validated against the tz
+// database on bind so an unknown zone falls back to UTC
+func location(tz string) *time.Location {
+	loc, err := time.LoadLocation(tz)
 	if err != nil {
-		return nil, err
-	}
-
-	txs := make([]*StatsPayload, 0)
-	volumes := make([]*StatsPayload, 0)
-	activeUsers := make([]*StatsPayload, 0)
-	newUsers := make([]*StatsPayload, 0)
-
-	for _, cmd := range cmds {
-		txs = append(txs, &StatsPayload{
-			Value: cmd.Count,
-			Time:  cmd.BucketTime.Unix(),
-		})
-	}
-
-	for _, token := range tokenSentSats {
-		volumes = append(volumes, &StatsPayload{
-			Value: token.TotalAmount,
-			Time:  token.BucketTime.Unix(),
-		})
-
-		activeUsers = append(activeUsers, &StatsPayload{
-			Value: token.ActiveUsers,
-			Time:  token.BucketTime.Unix(),
-		})
-
-		newUsers = append(newUsers, &StatsPayload{
-			Value: token.NewUsers,
-			Time:  token.BucketTime.Unix(),
-		})
+		return time.UTC
 	}
+	return loc
+}
 
-	response.Txs = txs
-	response.Volumes = volumes
-	response.ActiveUsers = activeUsers
-	response.NewUsers = newUsers
-	response = GetOverallStats(ctx, opts, response)
-	response = GetVolumeStats(ctx, opts, response)
-	response = GetTransactionStats(ctx, opts, response)
-	return response, nil
+type StatsPayload struct {
+	Value uint64 `json:"data"`
+	Time  int64  `json:"time"`
+	// Bucket start in RFC3339 with the offset of the requested time zone
+	Bucket string   `json:"bucket"`
+	USD    *float64 `json:"usd,omitempty"`
 }
 
-func GetOverallStats(ctx context.Context, opts *StatsOpts, response *StatsResponse) *StatsResponse {
-	totalTxs, err := db.GetTotalTxs()
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get total txs")
-	}
-	response.TotalTxs = totalTxs
-	response.TotalVolumes, err = db.GetTotalBridgedVolumes(opts.Network)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get total volumes")
-	}
-	response.TotalUsers, err = db.GetTotalUsers()
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get total users")
+func newStatsPayload(value uint64, bucket time.Time, loc *time.Location) *StatsPayload {
+	return &StatsPayload{
+		Value:  value,
+		Time:   bucket.Unix(),
+		Bucket: bucket.In(loc).Format(time.RFC3339),
 	}
-	return response
 }
 
 type SummaryStats struct {
-	TotalTxs     int64 `json:"total_txs"`
-	TotalVolumes int64 `json:"total_volumes"`
-	TotalUsers   int64 `json:"total_users"`
+	TotalTxs        int64    `json:"total_txs"`
+	TotalVolumes    int64    `json:"total_volumes"`
+	TotalVolumesUSD *float64 `json:"total_volumes_usd,omitempty"`
+	TotalUsers      int64    `json:"total_users"`
+
+	Window *WindowSummary `json:"window,omitempty"`
 }
 
+// GetSummaryStats fails when a total or the requested window fails, so that
+// a summary missing them is never cached. The USD volume is left out when it
+// can't be priced.
 func GetSummaryStats(ctx context.Context, opts *StatsOpts) (*SummaryStats, error) {
 	wg := sync.WaitGroup{}
 	var summary SummaryStats
-	lock := sync.Mutex{}
+	var txsErr, volumesErr, usersErr, windowErr error
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		totalTxs, err := db.GetTotalTxs()
-		if err != nil {
-			log.Error().Err(err).Msg("failed to get total txs")
-		}
-		lock.Lock()
-		summary.TotalTxs = totalTxs
-		lock.Unlock()
+		summary.TotalTxs, txsErr = db.GetTotalTxs(ctx)
 	}()
 	go func() {
 		defer wg.Done()
-		totalVolumes, err := db.GetTotalBridgedVolumes(opts.Network)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to get total volumes")
-		}
-		lock.Lock()
-		summary.TotalVolumes = totalVolumes
-		lock.Unlock()
+		summary.TotalVolumes, volumesErr = db.GetTotalBridgedVolumes(ctx, opts.Network)
 	}()
 	go func() {
 		defer wg.Done()
-		totalUsers, err := db.GetTotalUsers()
-		if err != nil {
-			log.Error().Err(err).Msg("failed to get total users")
-		}
-		lock.Lock()
-		summary.TotalUsers = totalUsers
-		lock.Unlock()
+		summary.TotalUsers, usersErr = db.GetTotalUsers(ctx)
 	}()
-	wg.Wait()
-	return &summary, nil
-}
-
-func GetVolumeStats(ctx context.Context, opts *StatsOpts, response *StatsResponse) *StatsResponse {
-	var err error
-	response.TopUsers, err = db.GetTopTransferUsers(opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top transfer users")
-	}
-	response.TopBridges, err = db.GetTopBridgeUsers(opts.Network, opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top bridge users")
+	if opts.USD {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			totalVolumesUSD, err := totalVolumeUSD(ctx, opts.Network)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get total volumes in usd")
+				return
+			}
+			summary.TotalVolumesUSD = &totalVolumesUSD
+		}()
+	}
+	if opts.Window != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			summary.Window, windowErr = GetWindowSummary(ctx, opts.Network, opts.Window)
+		}()
 	}
-	response.TopSourceChainsByVolume, err = db.StatVolumeBySourceChain(opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top source chains by volume")
-	}
-	response.TopDestinationChainsByVolume, err = db.StatVolumeByDestinationChain(opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top destination chains by volume")
-	}
-	response.TopPathsByVolume, err = db.StatVolumeByPath(opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top paths by volume")
-	}
-	return response
-}
+	wg.Wait()
 
-func GetTransactionStats(ctx context.Context, opts *StatsOpts, response *StatsResponse) *StatsResponse {
-	var err error
-	response.TopSourceChainsByTx, err = db.StatTransactionBySourceChain(opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top source chains by tx")
-	}
-	response.TopDestinationChainsByTx, err = db.StatTransactionByDestinationChain(opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top destination chains by tx")
-	}
-	response.TopPathsByTx, err = db.StatTransactionByPath(opts.Limit)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get top paths by tx")
+	if err := errors.Join(txsErr, volumesErr, usersErr, windowErr); err != nil {
+		return nil, err
 	}
-	return response
+	return &summary, nil
 }
 
+// GetTxsChartData counts the bridges and token calls, the global chart is the
+// drill-down chart without filter so that both count the same transactions
 func GetTxsChartData(ctx context.Context, opts *StatsOpts) ([]*StatsPayload, error) {
-	cmds, err := db.GetCommandStats(ctx, opts.TimeBucket, opts.Limit)
-	if err != nil {
-		return nil, err
-	}
-	txs := make([]*StatsPayload, 0)
-	for _, cmd := range cmds {
-		txs = append(txs, &StatsPayload{
-			Value: cmd.Count,
-			Time:  cmd.BucketTime.Unix(),
-		})
-	}
-	return txs, nil
+	return getChartData(ctx, db.ChartTxs, opts)
 }
 
 // func GetTxsStats(ctx context.Context, opts *StatsOpts) ([]*StatsPayload, error) {
@@ -225,49 +136,57 @@ func GetTxsChartData(ctx context.Context, opts *StatsOpts) ([]*StatsPayload, err
 // }
 
 func GetVolumesStats(ctx context.Context, opts *StatsOpts) ([]*StatsPayload, error) {
+	if opts.DrillDown() {
+		return getChartData(ctx, db.ChartVolumes, opts)
+	}
 	//tokenSentSats, err := db.GetTokenStats(opts.TimeBucket)
-	tokenSentSats, err := db.GetVolumeByTimeBucket(opts.TimeBucket, opts.Limit)
+	loc := opts.location()
+	tokenSentSats, err := db.GetVolumeByTimeBucket(ctx, opts.TimeBucket, loc.String(), opts.Limit)
 	if err != nil {
 		return nil, err
 	}
 	volumes := make([]*StatsPayload, 0)
 	for _, token := range tokenSentSats {
-		volumes = append(volumes, &StatsPayload{
-			Value: token.TotalAmount,
-			Time:  token.BucketTime.Unix(),
-		})
+		volumes = append(volumes, newStatsPayload(token.TotalAmount, token.BucketTime, loc))
+	}
+	if opts.USD {
+		if err := valueVolumes(ctx, opts.TimeBucket, volumes); err != nil {
+			return nil, err
+		}
 	}
 	return volumes, nil
 }
 
 func GetActiveUsersStats(ctx context.Context, opts *StatsOpts) ([]*StatsPayload, error) {
+	if opts.DrillDown() {
+		return getChartData(ctx, db.ChartActiveUsers, opts)
+	}
 	//tokenSentSats, err := db.GetTokenStats(opts.TimeBucket)
-	tokenSentSats, err := db.GetActiveUsersByTimeBucket(opts.TimeBucket, opts.Limit)
+	loc := opts.location()
+	tokenSentSats, err := db.GetActiveUsersByTimeBucket(ctx, opts.TimeBucket, loc.String(), opts.Limit)
 	if err != nil {
 		return nil, err
 	}
 	activeUsers := make([]*StatsPayload, 0)
 	for _, token := range tokenSentSats {
-		activeUsers = append(activeUsers, &StatsPayload{
-			Value: token.ActiveUsers,
-			Time:  token.BucketTime.Unix(),
-		})
+		activeUsers = append(activeUsers, newStatsPayload(token.ActiveUsers, token.BucketTime, loc))
 	}
 	return activeUsers, nil
 }
 
 func GetNewUsersStats(ctx context.Context, opts *StatsOpts) ([]*StatsPayload, error) {
+	if opts.DrillDown() {
+		return getChartData(ctx, db.ChartNewUsers, opts)
+	}
 	//tokenSentSats, err := db.GetTokenStats(opts.TimeBucket)
-	tokenSentSats, err := db.GetNewUsersByTimeBucket(opts.TimeBucket, opts.Limit)
+	loc := opts.location()
+	tokenSentSats, err := db.GetNewUsersByTimeBucket(ctx, opts.TimeBucket, loc.String(), opts.Limit)
 	if err != nil {
 		return nil, err
 	}
 	newUsers := make([]*StatsPayload, 0)
 	for _, token := range tokenSentSats {
-		newUsers = append(newUsers, &StatsPayload{
-			Value: token.NewUsers,
-			Time:  token.BucketTime.Unix(),
-		})
+		newUsers = append(newUsers, newStatsPayload(token.NewUsers, token.BucketTime, loc))
 	}
 	return newUsers, nil
 }
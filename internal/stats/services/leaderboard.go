@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const cursorPrefix = "offset:"
+
+// LeaderboardOpts pages through a leaderboard with either page or cursor, over
+// a period or an explicit from/to unix time range
+type LeaderboardOpts struct {
+	Limit  int    `query:"limit" validate:"omitempty,min=1,max=100"`
+	Size   int    `query:"size" validate:"omitempty,min=1,max=100"`
+	Page   int    `query:"page" validate:"omitempty,min=0"`
+	Cursor string `query:"cursor"`
+	Period string `query:"period" validate:"omitempty,oneof=7d 30d all"`
+	From   int64  `query:"from" validate:"omitempty,min=0"`
+	To     int64  `query:"to" validate:"omitempty,gtfield=From"`
+	Chain  string `query:"chain"`
+	// Asset ranking the top users, BTC by default
+	Asset string `query:"asset"`
+
+	Offset int `query:"-"`
+}
+
+// Normalize folds limit aliases into Limit and page or cursor into Offset, so
+// that equivalent requests share a cache key
+func (o *LeaderboardOpts) Normalize() error {
+	if o.Limit == 0 {
+		if o.Size > 0 {
+			o.Limit = o.Size
+		} else {
+			o.Limit = 10
+		}
+	}
+	o.Size = 0
+
+	if o.Cursor != "" {
+		offset, err := decodeCursor(o.Cursor)
+		if err != nil {
+			return err
+		}
+		o.Offset = offset
+	} else {
+		o.Offset = o.Page * o.Limit
+	}
+	o.Page, o.Cursor = 0, ""
+
+	// An explicit range wins over a period
+	if o.From > 0 || o.To > 0 || o.Period == "all" {
+		o.Period = ""
+	}
+	return nil
+}
+
+// Query resolves the options into a leaderboard query, periods end now
+func (o *LeaderboardOpts) Query() db.LeaderboardQuery {
+	q := db.LeaderboardQuery{
+		Limit:  o.Limit,
+		Offset: o.Offset,
+		From:   o.From,
+		To:     o.To,
+		Asset:  o.Asset,
+	}
+	if d, ok := windowDurations[o.Period]; ok {
+		q.From = time.Now().Add(-d).Unix()
+	}
+	return q
+}
+
+// NextCursor returns the cursor of the page after one of n rows, or an empty
+// string when it was the last page
+func (o *LeaderboardOpts) NextCursor(n int) string {
+	if n < o.Limit {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(o.Offset+n)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(b), cursorPrefix) {
+		return 0, ErrInvalidCursor
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(b), cursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: bad offset", ErrInvalidCursor)
+	}
+	return offset, nil
+}
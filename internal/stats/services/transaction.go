@@ -1,24 +1,26 @@
 package services
 
 import (
+	"context"
+
 	"github.com/scalarorg/scalar-service/pkg/db"
 	"github.com/scalarorg/scalar-service/pkg/types"
 )
 
-func StatTransactionBySourceChain(limit int) ([]*types.ChainAmount, error) {
-	return db.StatTransactionBySourceChain(limit)
+func StatTransactionBySourceChain(ctx context.Context, opts *LeaderboardOpts) ([]*types.ChainAmount, error) {
+	return db.StatTransactionBySourceChain(ctx, opts.Query())
 }
 
-func StatTransactionByDestinationChain(limit int) ([]*types.ChainAmount, error) {
-	stats, err := db.StatTransactionByDestinationChain(limit)
+func StatTransactionByDestinationChain(ctx context.Context, opts *LeaderboardOpts) ([]*types.ChainAmount, error) {
+	stats, err := db.StatTransactionByDestinationChain(ctx, opts.Query())
 	if err != nil {
 		return nil, err
 	}
 	return stats, nil
 }
 
-func StatTransactionByPath(limit int) ([]*types.PathAmount, error) {
-	stats, err := db.StatTransactionByPath(limit)
+func StatTransactionByPath(ctx context.Context, opts *LeaderboardOpts) ([]*types.PathAmount, error) {
+	stats, err := db.StatTransactionByPath(ctx, opts.Query())
 	if err != nil {
 		return nil, err
 	}
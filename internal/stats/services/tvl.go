@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+// TVLOpts selects the vault flows of a TVL series. Network is a bitcoin chain
+// and Chain the destination chain the bridged tokens are held on, both match
+// every chain when empty.
+type TVLOpts struct {
+	Network    string `query:"network"`
+	Chain      string `query:"chain"`
+	TimeBucket string `query:"time_bucket" validate:"omitempty,oneof=day week"`
+	Limit      int    `query:"limit" validate:"omitempty,min=1,max=365"`
+	TZ         string `query:"tz" validate:"omitempty,timezone"`
+}
+
+// TVLPoint is the value locked at the end of a bucket and the flows within it.
+// Networks and Chains break the TVL down per bitcoin network and per
+// destination chain.
+type TVLPoint struct {
+	Time     int64            `json:"time"`
+	Bucket   string           `json:"bucket"`
+	Inflow   uint64           `json:"inflow"`
+	Outflow  uint64           `json:"outflow"`
+	NetFlow  int64            `json:"net_flow"`
+	TVL      int64            `json:"tvl"`
+	Networks map[string]int64 `json:"networks"`
+	Chains   map[string]int64 `json:"chains"`
+}
+
+// ChainTVL is the value currently locked for a chain and its flows since the
+// first bridge
+type ChainTVL struct {
+	Chain   string `json:"chain"`
+	TVL     int64  `json:"tvl"`
+	Inflow  uint64 `json:"inflow"`
+	Outflow uint64 `json:"outflow"`
+}
+
+type TVL struct {
+	TVL      int64       `json:"tvl"`
+	Networks []*ChainTVL `json:"networks"`
+	Chains   []*ChainTVL `json:"chains"`
+	Series   []*TVLPoint `json:"series"`
+}
+
+// GetTVL accumulates the vault flows since the first bridge and returns the
+// last opts.Limit buckets of the series. Amounts are in satoshis.
+func GetTVL(ctx context.Context, opts *TVLOpts) (*TVL, error) {
+	loc := location(opts.TZ)
+	flows, err := db.GetVaultFlows(ctx, opts.TimeBucket, loc.String(), opts.Network, opts.Chain)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make(map[string]*ChainTVL)
+	chains := make(map[string]*ChainTVL)
+	series := make([]*TVLPoint, 0)
+	var point *TVLPoint
+	var tvl int64
+	for _, flow := range flows {
+		if point == nil || flow.BucketTime.Unix() != point.Time {
+			point = &TVLPoint{
+				Time:   flow.BucketTime.Unix(),
+				Bucket: flow.BucketTime.In(loc).Format(time.RFC3339),
+			}
+			series = append(series, point)
+		}
+		point.Inflow += flow.Inflow
+		point.Outflow += flow.Outflow
+		tvl += int64(flow.Inflow) - int64(flow.Outflow)
+		point.TVL = tvl
+		point.NetFlow = int64(point.Inflow) - int64(point.Outflow)
+		addFlow(networks, flow.Network, flow)
+		addFlow(chains, flow.Chain, flow)
+		point.Networks = snapshotTVL(networks)
+		point.Chains = snapshotTVL(chains)
+	}
+
+	if len(series) > opts.Limit {
+		series = series[len(series)-opts.Limit:]
+	}
+	return &TVL{
+		TVL:      tvl,
+		Networks: sortedTVL(networks),
+		Chains:   sortedTVL(chains),
+		Series:   series,
+	}, nil
+}
+
+func addFlow(tvls map[string]*ChainTVL, chain string, flow db.VaultFlow) {
+	t, ok := tvls[chain]
+	if !ok {
+		t = &ChainTVL{Chain: chain}
+		tvls[chain] = t
+	}
+	t.Inflow += flow.Inflow
+	t.Outflow += flow.Outflow
+	t.TVL = int64(t.Inflow) - int64(t.Outflow)
+}
+
+func snapshotTVL(tvls map[string]*ChainTVL) map[string]int64 {
+	snapshot := make(map[string]int64, len(tvls))
+	for chain, t := range tvls {
+		snapshot[chain] = t.TVL
+	}
+	return snapshot
+}
+
+// sortedTVL orders the chains by value locked, largest first
+func sortedTVL(tvls map[string]*ChainTVL) []*ChainTVL {
+	sorted := make([]*ChainTVL, 0, len(tvls))
+	for _, t := range tvls {
+		sorted = append(sorted, t)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].TVL != sorted[j].TVL {
+			return sorted[i].TVL > sorted[j].TVL
+		}
+		return sorted[i].Chain < sorted[j].Chain
+	})
+	return sorted
+}
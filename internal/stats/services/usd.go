@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/price"
+)
+
+// Bridged amounts are in satoshis
+const (
+	btcSymbol   = "BTC"
+	btcDecimals = 8
+)
+
+// valueVolumes sets the USD value of each volume bucket. Prices are daily, so
+// buckets of a day or longer are valued day by day and summed up.
+func valueVolumes(ctx context.Context, timeBucket string, volumes []*StatsPayload) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+
+	if timeBucket == "hour" {
+		hours := make([]time.Time, len(volumes))
+		for i, v := range volumes {
+			hours[i] = time.Unix(v.Time, 0)
+		}
+		prices, err := price.DailyPrices(ctx, btcSymbol, hours)
+		if err != nil {
+			return err
+		}
+		for i, v := range volumes {
+			usd := price.Value(v.Value, btcDecimals, prices[price.Day(hours[i])])
+			v.USD = &usd
+		}
+		return nil
+	}
+
+	daily, err := db.GetDailyBridgedVolumes(ctx, "", volumes[0].Time)
+	if err != nil {
+		return err
+	}
+
+	prices, err := dailyPrices(ctx, daily)
+	if err != nil {
+		return err
+	}
+
+	totals := make([]float64, len(volumes))
+	for _, day := range daily {
+		// volumes are sorted by time, find the bucket the day falls into
+		idx := sort.Search(len(volumes), func(i int) bool {
+			return volumes[i].Time > day.BucketTime.Unix()
+		}) - 1
+		if idx < 0 {
+			continue
+		}
+		totals[idx] += price.Value(day.TotalAmount, btcDecimals, prices[price.Day(day.BucketTime)])
+	}
+
+	for i := range volumes {
+		volumes[i].USD = &totals[i]
+	}
+	return nil
+}
+
+// totalVolumeUSD values every bridged satoshi on chain at the price of the day it was bridged
+func totalVolumeUSD(ctx context.Context, chain string) (float64, error) {
+	daily, err := db.GetDailyBridgedVolumes(ctx, chain, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	prices, err := dailyPrices(ctx, daily)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for _, day := range daily {
+		total += price.Value(day.TotalAmount, btcDecimals, prices[price.Day(day.BucketTime)])
+	}
+	return total, nil
+}
+
+// dailyPrices looks the prices of every day up at once
+func dailyPrices(ctx context.Context, daily []db.TokenSentStats) (map[string]float64, error) {
+	days := make([]time.Time, len(daily))
+	for i, day := range daily {
+		days[i] = day.BucketTime
+	}
+	return price.DailyPrices(ctx, btcSymbol, days)
+}
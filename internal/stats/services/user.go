@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/identity"
+	"github.com/scalarorg/scalar-service/pkg/types"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+var evmAddressRegex = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// UserRank is the position of a user on the leaderboards, 0 when not ranked
+type UserRank struct {
+	Bridges int64 `json:"bridges"`
+	Users   int64 `json:"users"`
+}
+
+type UserProfile struct {
+	Address         string              `json:"address"`
+	LinkedAddresses []string            `json:"linked_addresses"`
+	FirstSeen       int64               `json:"first_seen"`
+	LastSeen        int64               `json:"last_seen"`
+	Bridged         []types.AssetAmount `json:"bridged"`
+	Transferred     []types.AssetAmount `json:"transferred"`
+	Redeemed        []types.AssetAmount `json:"redeemed"`
+	// Amount of each path is its number of transactions
+	Paths []*types.PathAmount `json:"paths"`
+	Rank  UserRank            `json:"rank"`
+}
+
+// NormalizeUserAddress returns the canonical spelling of a bitcoin or EVM
+// address, so that every spelling of an address shares its cache entries
+func NormalizeUserAddress(address string) (string, error) {
+	address = identity.Normalize(address)
+	if evmAddressRegex.MatchString(address) {
+		return address, nil
+	}
+	script, err := utils.AddressToScriptPubKey(address, config.Env.BITCOIN_CHAIN_ID)
+	if err != nil {
+		return "", ErrInvalidAddress
+	}
+	return identity.ScriptToAddress(script, config.Env.BITCOIN_CHAIN_ID), nil
+}
+
+// GetUserProfile returns the footprint of a bitcoin or EVM address, or nil if
+// the address never used the bridge
+func GetUserProfile(ctx context.Context, address string) (*UserProfile, error) {
+	address, err := NormalizeUserAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var script, evmAddress string
+	if identity.IsEVMAddress(address) {
+		evmAddress = address
+	} else if script, err = utils.AddressToScriptPubKey(address, config.Env.BITCOIN_CHAIN_ID); err != nil {
+		return nil, ErrInvalidAddress
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	var activity []db.UserActivity
+	var userRank *db.UserIdentityRank
+	var activityErr, userRankErr error
+	var bridgeRank int64
+	var bridgeRankErr error
+
+	go func() {
+		defer wg.Done()
+		activity, activityErr = db.GetUserActivity(ctx, script, evmAddress)
+	}()
+	go func() {
+		defer wg.Done()
+		userRank, userRankErr = db.GetUserIdentityRank(ctx, script, evmAddress)
+	}()
+	if script != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bridgeRank, bridgeRankErr = db.GetBridgeRank(ctx, config.Env.BITCOIN_CHAIN_ID, script)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(activityErr, userRankErr, bridgeRankErr); err != nil {
+		return nil, err
+	}
+	if len(activity) == 0 {
+		return nil, nil
+	}
+
+	profile := &UserProfile{
+		Address:         address,
+		LinkedAddresses: make([]string, 0),
+		Bridged:         make([]types.AssetAmount, 0),
+		Transferred:     make([]types.AssetAmount, 0),
+		Redeemed:        make([]types.AssetAmount, 0),
+		Paths:           make([]*types.PathAmount, 0),
+		Rank:            UserRank{Bridges: bridgeRank, Users: userRank.Rank},
+	}
+
+	assets := map[db.CrossChainTx]map[string]*types.AssetAmount{}
+	paths := map[[2]string]*types.PathAmount{}
+	for _, a := range activity {
+		if a.FirstSeen > 0 && (profile.FirstSeen == 0 || a.FirstSeen < profile.FirstSeen) {
+			profile.FirstSeen = a.FirstSeen
+		}
+		if a.LastSeen > profile.LastSeen {
+			profile.LastSeen = a.LastSeen
+		}
+
+		if assets[a.Kind] == nil {
+			assets[a.Kind] = map[string]*types.AssetAmount{}
+		}
+		asset, ok := assets[a.Kind][a.Asset]
+		if !ok {
+			asset = &types.AssetAmount{Asset: a.Asset}
+			assets[a.Kind][a.Asset] = asset
+		}
+		asset.Amount += a.Amount
+		asset.Count += a.Count
+
+		key := [2]string{a.SourceChain, a.DestinationChain}
+		path, ok := paths[key]
+		if !ok {
+			path = &types.PathAmount{SourceChain: a.SourceChain, DestinationChain: a.DestinationChain}
+			paths[key] = path
+			profile.Paths = append(profile.Paths, path)
+		}
+		path.Amount += a.Count
+	}
+
+	profile.Bridged = sortedAssets(assets[db.CrossChainTxBridge])
+	profile.Transferred = sortedAssets(assets[db.CrossChainTxTransfer])
+	profile.Redeemed = sortedAssets(assets[db.CrossChainTxRedeem])
+	sort.Slice(profile.Paths, func(i, j int) bool {
+		return profile.Paths[i].Amount > profile.Paths[j].Amount
+	})
+
+	id := identity.New(userRank.Script, userRank.Network, userRank.Address, strings.Split(userRank.Linked, ","))
+	for _, linked := range id.Addresses {
+		if linked != address {
+			profile.LinkedAddresses = append(profile.LinkedAddresses, linked)
+		}
+	}
+
+	return profile, nil
+}
+
+func sortedAssets(assets map[string]*types.AssetAmount) []types.AssetAmount {
+	sorted := make([]types.AssetAmount, 0, len(assets))
+	for _, a := range assets {
+		sorted = append(sorted, *a)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Amount > sorted[j].Amount
+	})
+	return sorted
+}
@@ -1,26 +1,28 @@
 package services
 
 import (
+	"context"
+
 	"github.com/scalarorg/scalar-service/pkg/db"
 	"github.com/scalarorg/scalar-service/pkg/types"
 )
 
-func GetTopUsersByVolume(limit int) ([]types.AddressAmount, error) {
-	return db.GetTopTransferUsers(limit)
+func GetTopUsersByVolume(ctx context.Context, opts *LeaderboardOpts) ([]types.AddressAmount, error) {
+	return db.GetTopTransferUsers(ctx, opts.Query())
 }
 
-func GetTopBridgesByVolume(sourceChain string, limit int) ([]*types.AddressAmount, error) {
-	return db.GetTopBridgeUsers(sourceChain, limit)
+func GetTopBridgesByVolume(ctx context.Context, opts *LeaderboardOpts) ([]*types.AddressAmount, error) {
+	return db.GetTopBridgeUsers(ctx, opts.Chain, opts.Query())
 }
 
-func GetTopSourceChainsByVolume(limit int) ([]*types.ChainAmount, error) {
-	return db.StatVolumeBySourceChain(limit)
+func GetTopSourceChainsByVolume(ctx context.Context, opts *LeaderboardOpts) ([]*types.ChainAmount, error) {
+	return db.StatVolumeBySourceChain(ctx, opts.Query())
 }
 
-func GetTopDestinationChainsByVolume(limit int) ([]*types.ChainAmount, error) {
-	return db.StatVolumeByDestinationChain(limit)
+func GetTopDestinationChainsByVolume(ctx context.Context, opts *LeaderboardOpts) ([]*types.ChainAmount, error) {
+	return db.StatVolumeByDestinationChain(ctx, opts.Query())
 }
 
-func GetTopPathsByVolume(limit int) ([]*types.PathAmount, error) {
-	return db.StatVolumeByPath(limit)
+func GetTopPathsByVolume(ctx context.Context, opts *LeaderboardOpts) ([]*types.PathAmount, error) {
+	return db.StatVolumeByPath(ctx, opts.Query())
 }
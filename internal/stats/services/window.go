@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+var windowDurations = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// MetricChange compares a metric over a window with the window before it.
+// ChangePercent is null when the previous window is zero.
+type MetricChange struct {
+	Current       int64    `json:"current"`
+	Previous      int64    `json:"previous"`
+	Change        int64    `json:"change"`
+	ChangePercent *float64 `json:"change_percent"`
+}
+
+type WindowSummary struct {
+	Window      string       `json:"window"`
+	From        int64        `json:"from"`
+	To          int64        `json:"to"`
+	Txs         MetricChange `json:"txs"`
+	Volumes     MetricChange `json:"volumes"`
+	ActiveUsers MetricChange `json:"active_users"`
+	NewUsers    MetricChange `json:"new_users"`
+}
+
+func newMetricChange(current, previous int64) MetricChange {
+	m := MetricChange{
+		Current:  current,
+		Previous: previous,
+		Change:   current - previous,
+	}
+	if previous != 0 {
+		percent := float64(m.Change) / float64(previous) * 100
+		m.ChangePercent = &percent
+	}
+	return m
+}
+
+// GetWindowSummary computes the window ending now and the previous window of
+// the same length in parallel
+func GetWindowSummary(ctx context.Context, chain string, window string) (*WindowSummary, error) {
+	d, ok := windowDurations[window]
+	if !ok {
+		return nil, fmt.Errorf("invalid window %q", window)
+	}
+
+	to := time.Now().Unix()
+	from := to - int64(d.Seconds())
+	prevFrom := from - int64(d.Seconds())
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+	var current, previous *db.WindowStats
+	var currentErr, previousErr error
+	go func() {
+		defer wg.Done()
+		current, currentErr = db.GetWindowStats(ctx, chain, from, to)
+	}()
+	go func() {
+		defer wg.Done()
+		previous, previousErr = db.GetWindowStats(ctx, chain, prevFrom, from)
+	}()
+	wg.Wait()
+
+	if currentErr != nil {
+		return nil, currentErr
+	}
+	if previousErr != nil {
+		return nil, previousErr
+	}
+
+	return &WindowSummary{
+		Window:      window,
+		From:        from,
+		To:          to,
+		Txs:         newMetricChange(current.Txs, previous.Txs),
+		Volumes:     newMetricChange(current.Volume, previous.Volume),
+		ActiveUsers: newMetricChange(current.ActiveUsers, previous.ActiveUsers),
+		NewUsers:    newMetricChange(current.NewUsers, previous.NewUsers),
+	}, nil
+}
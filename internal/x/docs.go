@@ -0,0 +1,19 @@
+package x
+
+import (
+	"github.com/scalarorg/scalar-service/internal/x/handlers"
+	"github.com/scalarorg/scalar-service/internal/x/services"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/openapi"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+func Docs() []openapi.Operation {
+	return []openapi.Operation{
+		{Handler: handlers.ListWithQuery, Summary: "List cross-chain transactions", Params: services.ListOptions{}, Response: utils.ListPublicResponse[[]*db.CrossChainDocument]{}, Data: []*db.CrossChainDocument{}},
+		{Handler: handlers.List, Summary: "List cross-chain transactions", Body: services.ListOptions{}, Response: utils.ListPublicResponse[[]*db.CrossChainDocument]{}, Data: []*db.CrossChainDocument{}},
+		{Handler: handlers.Export, Summary: "Export the latest transactions as CSV", Description: "Requires an API key with the export scope.", Params: services.ExportOptions{}, ContentType: "text/csv", Security: openapi.SecurityAPIKey},
+		{Handler: handlers.Batch, Summary: "Look up several transactions", Description: "Requires an API key with the batch scope. Transactions not found are null.", Body: services.BatchOptions{}, Response: []*db.CrossChainDocument{}, Security: openapi.SecurityAPIKey},
+		{Handler: handlers.Get, Summary: "Cross-chain transaction", Params: services.GetOptions{}, Response: db.CrossChainDocument{}},
+	}
+}
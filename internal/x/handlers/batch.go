@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/constants"
+	"github.com/scalarorg/scalar-service/internal/x/services"
+	"github.com/scalarorg/scalar-service/pkg/price"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// Batch looks up to 100 transactions in one request, it needs the batch scope
+func Batch(c echo.Context) error {
+	var opts services.BatchOptions
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+
+	if opts.USD && !price.Enabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, constants.ErrPriceDisabled)
+	}
+
+	docs, err := services.BatchGet(c.Request().Context(), &opts)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return utils.JSON(c, http.StatusOK, docs)
+}
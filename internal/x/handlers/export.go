@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/x/services"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// Export downloads the latest transactions of a type as CSV, it needs the
+// export scope
+func Export(c echo.Context) error {
+	var opts services.ExportOptions
+	if err := utils.BindAndValidate(c, &opts); err != nil {
+		return err
+	}
+	if opts.Size == 0 {
+		opts.Size = 1000
+	}
+
+	var buf bytes.Buffer
+	if err := services.Export(c.Request().Context(), &buf, &opts); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+opts.Type+`-transactions.csv"`)
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
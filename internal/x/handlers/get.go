@@ -4,7 +4,10 @@ import (
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/constants"
 	"github.com/scalarorg/scalar-service/internal/x/services"
+	"github.com/scalarorg/scalar-service/pkg/price"
 	"github.com/scalarorg/scalar-service/pkg/utils"
 )
 
@@ -17,10 +20,19 @@ func Get(c echo.Context) error {
 		return err
 	}
 
+	if req.USD && !price.Enabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, constants.ErrPriceDisabled)
+	}
+
 	tx, err := services.Get(ctx, &req)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	return c.JSON(http.StatusOK, tx)
+	// Pending transactions get the short policy of the route, see middleware.HTTPCache
+	if tx.Executed() {
+		utils.CacheImmutable(c, config.Reloadable().HTTP_CACHE_EXECUTED_MAX_AGE)
+	}
+
+	return utils.JSON(c, http.StatusOK, tx)
 }
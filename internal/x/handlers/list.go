@@ -5,7 +5,9 @@ import (
 	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/constants"
 	"github.com/scalarorg/scalar-service/internal/x/services"
+	"github.com/scalarorg/scalar-service/pkg/price"
 	"github.com/scalarorg/scalar-service/pkg/utils"
 )
 
@@ -18,12 +20,16 @@ func List(c echo.Context) error {
 		return err
 	}
 
+	if body.USD && !price.Enabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, constants.ErrPriceDisabled)
+	}
+
 	txs, count, err := services.List(ctx, &body)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	return c.JSON(http.StatusOK, utils.NewListResult(txs, count))
+	return utils.ListJSON(c, txs, count, body.Page, body.Size)
 }
 
 func ListWithQuery(c echo.Context) error {
@@ -55,10 +61,26 @@ func ListWithQuery(c echo.Context) error {
 		options.Type = typeParam
 	}
 
+	// Parse usd parameter, a malformed value is rejected
+	if usdStr := c.QueryParam("usd"); usdStr != "" {
+		usd, err := strconv.ParseBool(usdStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid usd, expected a boolean")
+		}
+		options.USD = usd
+	}
+	if err := c.Validate(&options); err != nil {
+		return err
+	}
+
+	if options.USD && !price.Enabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, constants.ErrPriceDisabled)
+	}
+
 	txs, count, err := services.List(ctx, &options)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err)
 	}
 
-	return c.JSON(http.StatusOK, utils.NewListResult(txs, count))
+	return utils.ListJSON(c, txs, count, options.Page, options.Size)
 }
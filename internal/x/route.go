@@ -2,7 +2,9 @@ package x
 
 import (
 	"github.com/labstack/echo/v4"
+	"github.com/scalarorg/scalar-service/internal/middleware"
 	"github.com/scalarorg/scalar-service/internal/x/handlers"
+	"github.com/scalarorg/scalar-service/pkg/apikey"
 )
 
 func Route(g *echo.Group, path string) {
@@ -10,5 +12,7 @@ func Route(g *echo.Group, path string) {
 
 	x.GET("", handlers.ListWithQuery)
 	x.POST("", handlers.List)
+	x.GET("/export", handlers.Export, middleware.RequireScope(apikey.ScopeExport))
+	x.POST("/batch", handlers.Batch, middleware.RequireScope(apikey.ScopeBatch))
 	x.GET("/:type/:tx_hash", handlers.Get)
 }
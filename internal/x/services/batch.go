@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+)
+
+// batchConcurrency bounds the lookups of a batch running at once
+const batchConcurrency = 8
+
+type Lookup struct {
+	Type   string `json:"type" validate:"required,oneof=bridge transfer redeem"`
+	TxHash string `json:"tx_hash" validate:"required"`
+}
+
+type BatchOptions struct {
+	Lookups []Lookup `json:"lookups" validate:"required,min=1,max=100,dive"`
+	USD     bool     `json:"usd"`
+}
+
+// BatchGet looks up several transactions, the documents are in the order of
+// the lookups and nil for the transactions not found. They are valued in USD
+// together once all are found.
+func BatchGet(ctx context.Context, opts *BatchOptions) ([]*db.CrossChainDocument, error) {
+	docs := make([]*db.CrossChainDocument, len(opts.Lookups))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(batchConcurrency)
+	for i, lookup := range opts.Lookups {
+		g.Go(func() error {
+			doc, err := Get(gctx, &GetOptions{Type: lookup.Type, TxHash: lookup.TxHash})
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			docs[i] = doc
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if opts.USD {
+		valueDocuments(ctx, docs)
+	}
+	return docs, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+type ExportOptions struct {
+	Type string `query:"type" validate:"required,oneof=bridge transfer redeem"`
+	// Number of the latest transactions exported, 1000 by default
+	Size int `query:"size" validate:"omitempty,min=1,max=10000"`
+}
+
+var exportHeader = []string{
+	"id", "type", "status",
+	"source_chain", "source_tx_hash", "sender", "block_time",
+	"destination_chain", "destination_tx_hash", "receiver",
+	"symbol", "value",
+}
+
+// Export writes the latest transactions of a type to w as CSV
+func Export(ctx context.Context, w io.Writer, opts *ExportOptions) error {
+	docs, _, err := List(ctx, &ListOptions{Type: opts.Type, Size: opts.Size})
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := cw.Write(exportRecord(doc)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func exportRecord(doc *db.CrossChainDocument) []string {
+	record := make([]string, len(exportHeader))
+	record[0], record[1], record[2] = doc.ID, string(doc.Type), doc.Status
+	if doc.Source != nil && doc.Source.BaseDocument != nil {
+		record[3] = doc.Source.Chain
+		record[4] = doc.Source.TxHash
+		record[5] = doc.Source.Sender
+		record[6] = strconv.FormatUint(doc.Source.BlockTime, 10)
+		record[10] = doc.Source.CrossChainAsset.Symbol
+		record[11] = doc.Source.Value
+	}
+	if doc.Destination != nil && doc.Destination.BaseDocument != nil {
+		record[7] = doc.Destination.Chain
+		record[8] = doc.Destination.TxHash
+		record[9] = doc.Destination.Receiver
+	}
+	return record
+}
@@ -10,6 +10,7 @@ import (
 type GetOptions struct {
 	TxHash string `param:"tx_hash" validate:"required"`
 	Type   string `param:"type" validate:"omitempty,oneof=bridge transfer redeem"`
+	USD    bool   `query:"usd"`
 }
 
 func Get(ctx context.Context, options *GetOptions) (*db.CrossChainDocument, error) {
@@ -33,6 +34,9 @@ func Get(ctx context.Context, options *GetOptions) (*db.CrossChainDocument, erro
 	}
 
 	result := db.CreateCrossChainDocument(tx)
+	if options.USD {
+		valueDocuments(ctx, []*db.CrossChainDocument{result})
+	}
 
 	return result, nil
 }
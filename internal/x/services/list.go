@@ -12,6 +12,7 @@ type ListOptions struct {
 	Size int    `json:"size,omitempty"`
 	Page int    `json:"page,omitempty"`
 	Type string `json:"type,omitempty" validate:"omitempty,oneof=bridge transfer redeem"`
+	USD  bool   `json:"usd,omitempty"`
 }
 
 func List(ctx context.Context, options *ListOptions) ([]*db.CrossChainDocument, int, error) {
@@ -41,6 +42,9 @@ func List(ctx context.Context, options *ListOptions) ([]*db.CrossChainDocument,
 	}
 
 	list := utils.Map(txs, func(tx db.BaseCrossChainTxResult) *db.CrossChainDocument { return db.CreateCrossChainDocument(&tx) })
+	if options.USD {
+		valueDocuments(ctx, list)
+	}
 
 	return list, count, nil
 }
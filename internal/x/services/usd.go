@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/pkg/db"
+	"github.com/scalarorg/scalar-service/pkg/price"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// valuation is a transfer to value in USD at its source block time
+type valuation struct {
+	doc    *db.CrossChainDocument
+	amount uint64
+	at     time.Time
+}
+
+// valueDocuments sets the USD value of transfers at their source block time,
+// each symbol is priced once for the days of all the documents. A missing
+// price leaves the values unset rather than failing the request.
+func valueDocuments(ctx context.Context, docs []*db.CrossChainDocument) {
+	bySymbol := make(map[string][]valuation)
+	for _, doc := range docs {
+		if doc == nil || doc.Source == nil || doc.Source.BlockTime == 0 {
+			continue
+		}
+		amount, err := strconv.ParseUint(doc.Source.Value, 10, 64)
+		if err != nil {
+			continue
+		}
+		symbol := doc.Source.CrossChainAsset.Symbol
+		bySymbol[symbol] = append(bySymbol[symbol], valuation{doc: doc, amount: amount, at: time.Unix(int64(doc.Source.BlockTime), 0)})
+	}
+
+	for symbol, valuations := range bySymbol {
+		days := utils.Map(valuations, func(v valuation) time.Time { return v.at })
+		prices, err := price.DailyPrices(ctx, symbol, days)
+		if errors.Is(err, price.ErrUnknownSymbol) {
+			log.Warn().Err(err).Msg("failed to value transactions in usd")
+			continue
+		}
+		if err != nil {
+			prices = dailyPrices(ctx, symbol, days)
+		}
+
+		for _, v := range valuations {
+			p, ok := prices[price.Day(v.at)]
+			if !ok {
+				continue
+			}
+			usd := price.Value(v.amount, v.doc.Source.CrossChainAsset.Decimals, p)
+			v.doc.Source.USD = &usd
+			if v.doc.Destination != nil {
+				v.doc.Destination.USD = &usd
+			}
+		}
+	}
+}
+
+// dailyPrices prices the days of at one by one, so that a missing day only
+// leaves the documents of that day unvalued
+func dailyPrices(ctx context.Context, symbol string, at []time.Time) map[string]float64 {
+	prices := make(map[string]float64)
+	for _, t := range at {
+		day := price.Day(t)
+		if _, ok := prices[day]; ok {
+			continue
+		}
+		dayPrices, err := price.DailyPrices(ctx, symbol, []time.Time{t})
+		if err != nil {
+			log.Warn().Err(err).Str("symbol", symbol).Str("day", day).Msg("failed to value transactions in usd")
+			continue
+		}
+		prices[day] = dayPrices[day]
+	}
+	return prices
+}
@@ -0,0 +1,220 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+// Keys are sk_ followed by 32 random bytes in base64url. Only their SHA-256
+// is stored, they are random enough not to need a salt.
+const (
+	keyPrefix    = "sk_"
+	keyBytes     = 32
+	prefixLength = len(keyPrefix) + 8
+)
+
+// Scopes grant access to the endpoints heavier than the public reads, which
+// every client can use
+const (
+	ScopeRead    = "read"
+	ScopeExport  = "export"
+	ScopeBatch   = "batch"
+	ScopeGraphQL = "graphql"
+)
+
+var Scopes = []string{ScopeRead, ScopeExport, ScopeBatch, ScopeGraphQL}
+
+// Tiers scale the rate limit of the anonymous clients, the unlimited tier is
+// never limited
+const (
+	TierBasic     = "basic"
+	TierPartner   = "partner"
+	TierUnlimited = "unlimited"
+)
+
+var tierMultipliers = map[string]int{
+	TierBasic:     2,
+	TierPartner:   10,
+	TierUnlimited: 0,
+}
+
+var Tiers = []string{TierBasic, TierPartner, TierUnlimited}
+
+var (
+	ErrInvalidKey = errors.New("invalid api key")
+	ErrRevokedKey = errors.New("api key is revoked")
+	ErrExpiredKey = errors.New("api key is expired")
+	ErrDisabled   = errors.New("api keys are disabled")
+)
+
+// Store finds the keys by the hash of their secret
+type Store interface {
+	GetAPIKeyByHash(ctx context.Context, hash string) (*db.APIKey, error)
+}
+
+type dbStore struct{}
+
+func (dbStore) GetAPIKeyByHash(ctx context.Context, hash string) (*db.APIKey, error) {
+	return db.GetAPIKeyByHash(ctx, hash)
+}
+
+// Default is the database once the tables of the keys are created, the keys
+// are disabled while it is nil
+var Default Store
+
+// Enabled reports whether the keys can be used, they are disabled when their
+// tables couldn't be created
+func Enabled() bool {
+	return Default != nil
+}
+
+func HasScope(key *db.APIKey, scope string) bool {
+	return slices.Contains(key.Scopes, scope)
+}
+
+// QuotaMultiplier is the factor applied to the rate limit of a tier, 0 when
+// the tier is unlimited
+func QuotaMultiplier(tier string) int {
+	return tierMultipliers[tier]
+}
+
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Generate returns a new key and the prefix it is recognized by
+func Generate() (key string, prefix string, err error) {
+	b := make([]byte, keyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:prefixLength], nil
+}
+
+// Issue creates a key, the returned secret is never stored and can't be shown
+// again
+func Issue(ctx context.Context, name string, scopes []string, tier string, expiresAt *time.Time) (*db.APIKey, string, error) {
+	if !Enabled() {
+		return nil, "", ErrDisabled
+	}
+	secret, prefix, err := Generate()
+	if err != nil {
+		return nil, "", err
+	}
+	key, err := db.CreateAPIKey(ctx, &db.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   Hash(secret),
+		Scopes:    scopes,
+		Tier:      tier,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return key, secret, nil
+}
+
+// Revoke revokes a key. Other instances keep accepting it until their cached
+// lookup expires.
+func Revoke(ctx context.Context, id uint64) (*db.APIKey, error) {
+	key, err := db.RevokeAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	lookups.forget(key.KeyHash)
+	return key, nil
+}
+
+// Authenticate returns the key of a secret, which must be neither revoked nor
+// expired
+func Authenticate(ctx context.Context, secret string) (*db.APIKey, error) {
+	if !strings.HasPrefix(secret, keyPrefix) || len(secret) <= prefixLength {
+		return nil, ErrInvalidKey
+	}
+	if !Enabled() {
+		return nil, ErrDisabled
+	}
+
+	hash := Hash(secret)
+	key, err := lookups.get(hash, func() (*db.APIKey, error) {
+		key, err := Default.GetAPIKeyByHash(ctx, hash)
+		if errors.Is(err, db.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidKey
+		}
+		return key, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrRevokedKey
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return nil, ErrExpiredKey
+	}
+	return key, nil
+}
+
+// lookupTTL bounds the time a revoked key is still accepted by the instances
+// that didn't revoke it
+const (
+	lookupTTL  = 30 * time.Second
+	lookupSize = 10_000
+)
+
+type lookup struct {
+	key       *db.APIKey
+	err       error
+	expiresAt time.Time
+}
+
+// lookupCache caches the keys by hash, unknown keys included so invalid keys
+// don't reach the database on every request
+type lookupCache struct {
+	mu      sync.Mutex
+	lookups map[string]lookup
+}
+
+var lookups = &lookupCache{lookups: make(map[string]lookup)}
+
+func (c *lookupCache) get(hash string, load func() (*db.APIKey, error)) (*db.APIKey, error) {
+	c.mu.Lock()
+	l, ok := c.lookups[hash]
+	c.mu.Unlock()
+	if ok && time.Now().Before(l.expiresAt) {
+		return l.key, l.err
+	}
+
+	key, err := load()
+	if err != nil && !errors.Is(err, ErrInvalidKey) {
+		// Database errors are not cached
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.lookups) >= lookupSize {
+		c.lookups = make(map[string]lookup)
+	}
+	c.lookups[hash] = lookup{key: key, err: err, expiresAt: time.Now().Add(lookupTTL)}
+	return key, err
+}
+
+func (c *lookupCache) forget(hash string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.lookups, hash)
+}
@@ -0,0 +1,82 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+type testStore map[string]*db.APIKey
+
+func (s testStore) GetAPIKeyByHash(_ context.Context, hash string) (*db.APIKey, error) {
+	if key, ok := s[hash]; ok {
+		return key, nil
+	}
+	return nil, db.ErrAPIKeyNotFound
+}
+
+func TestAuthenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	store := testStore{}
+	secrets := make(map[string]string)
+	for name, key := range map[string]*db.APIKey{
+		"valid":    {ID: 1},
+		"expiring": {ID: 2, ExpiresAt: &future},
+		"revoked":  {ID: 3, RevokedAt: &past},
+		"expired":  {ID: 4, ExpiresAt: &past},
+	} {
+		secret, _, err := Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		secrets[name] = secret
+		store[Hash(secret)] = key
+	}
+	unknown, _, _ := Generate()
+
+	Default = store
+	defer func() { Default = nil }()
+
+	tests := []struct {
+		name    string
+		secret  string
+		wantID  uint64
+		wantErr error
+	}{
+		{"valid", secrets["valid"], 1, nil},
+		{"not expired yet", secrets["expiring"], 2, nil},
+		{"revoked", secrets["revoked"], 0, ErrRevokedKey},
+		{"expired", secrets["expired"], 0, ErrExpiredKey},
+		{"unknown", unknown, 0, ErrInvalidKey},
+		{"malformed", "not-a-key", 0, ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := Authenticate(context.Background(), tt.secret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && key.ID != tt.wantID {
+				t.Errorf("Authenticate() key = %d, want %d", key.ID, tt.wantID)
+			}
+		})
+	}
+
+	Default = nil
+	if _, err := Authenticate(context.Background(), secrets["valid"]); !errors.Is(err, ErrDisabled) {
+		t.Errorf("Authenticate() with disabled keys error = %v, want %v", err, ErrDisabled)
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	key := &db.APIKey{Scopes: []string{ScopeRead, ScopeExport}}
+	for scope, want := range map[string]bool{ScopeRead: true, ScopeExport: true, ScopeBatch: false, ScopeGraphQL: false} {
+		if got := HasScope(key, scope); got != want {
+			t.Errorf("HasScope(%q) = %v, want %v", scope, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package apikey
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+// usageInterval is how often the usage counted in memory is added to the
+// database, so requests don't each write to it
+const usageInterval = time.Minute
+
+// UsageCounter counts the requests of each key, the counts are added to the
+// usage of the day they are flushed on
+type UsageCounter struct {
+	mu       sync.Mutex
+	requests map[uint64]uint64
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+var usage *UsageCounter
+
+func Init() {
+	if !db.ServiceTablesReady() {
+		log.Warn().Msg("API keys are disabled, their requests are served as anonymous")
+		return
+	}
+	Default = dbStore{}
+	usage = NewUsageCounter()
+	usage.Start()
+	log.Info().Dur("interval", usageInterval).Msg("API key usage counter started")
+}
+
+// Close flushes the usage not yet added to the database
+func Close() {
+	if usage != nil {
+		usage.Stop()
+	}
+}
+
+// Record counts a request of a key
+func Record(key *db.APIKey) {
+	if usage != nil {
+		usage.Add(key.ID)
+	}
+}
+
+func NewUsageCounter() *UsageCounter {
+	return &UsageCounter{requests: make(map[uint64]uint64)}
+}
+
+func (u *UsageCounter) Add(id uint64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.requests[id]++
+}
+
+func (u *UsageCounter) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	u.cancel = cancel
+	u.done = make(chan struct{})
+
+	go func() {
+		defer close(u.done)
+		ticker := time.NewTicker(usageInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				u.flush(context.Background())
+				return
+			case <-ticker.C:
+				u.flush(ctx)
+			}
+		}
+	}()
+}
+
+func (u *UsageCounter) Stop() {
+	if u.cancel == nil {
+		return
+	}
+	u.cancel()
+	<-u.done
+}
+
+// flush adds the counted requests to the database, they are counted again
+// when it fails
+func (u *UsageCounter) flush(ctx context.Context) {
+	u.mu.Lock()
+	requests := u.requests
+	u.requests = make(map[uint64]uint64)
+	u.mu.Unlock()
+
+	if err := db.AddAPIKeyUsage(ctx, time.Now().UTC(), requests); err != nil {
+		log.Warn().Err(err).Msg("failed to flush api key usage")
+		u.mu.Lock()
+		for id, count := range requests {
+			u.requests[id] += count
+		}
+		u.mu.Unlock()
+	}
+}
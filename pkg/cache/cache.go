@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+	"golang.org/x/sync/singleflight"
+)
+
+// Entry is a cached value together with the time it stops being fresh.
+// Stale entries are still served while a refresh runs in the background.
+type Entry struct {
+	Value      []byte    `json:"value"`
+	FreshUntil time.Time `json:"fresh_until"`
+}
+
+func (e *Entry) IsFresh() bool {
+	return time.Now().Before(e.FreshUntil)
+}
+
+// Store is the storage backend of the cache. Expired entries must not be
+// returned by Get.
+type Store interface {
+	Get(ctx context.Context, key string) (*Entry, bool, error)
+	Set(ctx context.Context, key string, entry *Entry, expiration time.Duration) error
+	// Purge removes every key with the given prefix, or every key if prefix is empty
+	Purge(ctx context.Context, prefix string) (int, error)
+	Close() error
+}
+
+// Policy controls how long an entry is fresh and how long after that it may
+// still be served stale while being revalidated.
+type Policy struct {
+	TTL   time.Duration
+	Stale time.Duration
+}
+
+// fillTimeout bounds the loads, which are detached from the request that
+// started them as other requests may wait on them too
+const fillTimeout = time.Minute
+
+type Cache struct {
+	store Store
+	group singleflight.Group
+}
+
+var Default *Cache
+
+func New(store Store) *Cache {
+	return &Cache{store: store}
+}
+
+func Init() {
+	var store Store
+	switch config.Env.CACHE_DRIVER {
+	case "redis":
+		s, err := NewRedisStore(config.Env.REDIS_URL)
+		if err != nil {
+			panic(fmt.Sprintf("failed to connect to redis: %+v", err))
+		}
+		store = s
+	default:
+		store = NewMemoryStore(config.Env.CACHE_SIZE)
+	}
+	Default = New(store)
+	log.Info().Str("driver", config.Env.CACHE_DRIVER).Msg("Cache initialized")
+}
+
+func Close() error {
+	if Default == nil {
+		return nil
+	}
+	return Default.store.Close()
+}
+
+// Key builds a cache key from a route and its normalized options. Options are
+// serialized as JSON so struct field order keeps the key stable.
+func Key(route string, opts any) string {
+	b, err := json.Marshal(opts)
+	if err != nil {
+		return route
+	}
+	return route + "?" + string(b)
+}
+
+func (c *Cache) Purge(ctx context.Context, prefix string) (int, error) {
+	return c.store.Purge(ctx, prefix)
+}
+
+// Remember returns the cached value for key, loading it with load on a miss.
+// Concurrent misses for the same key share a single load, and stale entries
+// are returned immediately while one background load refreshes them.
+func Remember[T any](ctx context.Context, c *Cache, key string, policy Policy, load func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	if c == nil {
+		return load(ctx)
+	}
+
+	entry, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to read cache")
+	}
+
+	if ok {
+		if err := json.Unmarshal(entry.Value, &result); err == nil {
+			if !entry.IsFresh() {
+				c.revalidate(ctx, key, policy, func(ctx context.Context) (any, error) { return load(ctx) })
+			}
+			return result, nil
+		}
+	}
+
+	ch := c.group.DoChan(key, func() (any, error) {
+		fillCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fillTimeout)
+		defer cancel()
+		return c.fill(fillCtx, key, policy, func(ctx context.Context) (any, error) { return load(ctx) })
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return result, res.Err
+		}
+		return res.Val.(T), nil
+	case <-ctx.Done():
+		return result, ctx.Err()
+	}
+}
+
+func (c *Cache) revalidate(ctx context.Context, key string, policy Policy, load func(ctx context.Context) (any, error)) {
+	// Detach from the request so the refresh outlives it
+	ch := c.group.DoChan(key, func() (any, error) {
+		bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fillTimeout)
+		defer cancel()
+		return c.fill(bgCtx, key, policy, load)
+	})
+	go func() {
+		if res := <-ch; res.Err != nil {
+			log.Warn().Err(res.Err).Str("key", key).Msg("failed to revalidate cache entry")
+		}
+	}()
+}
+
+func (c *Cache) fill(ctx context.Context, key string, policy Policy, load func(ctx context.Context) (any, error)) (any, error) {
+	v, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to encode cache entry")
+		return v, nil
+	}
+
+	entry := &Entry{Value: b, FreshUntil: time.Now().Add(policy.TTL)}
+	if err := c.store.Set(ctx, key, entry, policy.TTL+policy.Stale); err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("failed to write cache")
+	}
+	return v, nil
+}
+
+func hasPrefix(key, prefix string) bool {
+	return prefix == "" || strings.HasPrefix(key, prefix)
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(2)
+	entry := &Entry{Value: []byte(`1`), FreshUntil: time.Now().Add(time.Minute)}
+
+	s.Set(ctx, "a", entry, time.Minute)
+	s.Set(ctx, "b", entry, time.Minute)
+	// Reading a makes b the least recently used
+	s.Get(ctx, "a")
+	s.Set(ctx, "c", entry, time.Minute)
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := s.Get(ctx, key); ok != want {
+			t.Errorf("Get(%q) found = %v, want %v", key, ok, want)
+		}
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(10)
+	s.Set(ctx, "a", &Entry{Value: []byte(`1`)}, 20*time.Millisecond)
+
+	if _, ok, _ := s.Get(ctx, "a"); !ok {
+		t.Fatal("entry expired early")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok, _ := s.Get(ctx, "a"); ok {
+		t.Error("expired entry returned")
+	}
+}
+
+func TestMemoryStorePurge(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(10)
+	for _, key := range []string{"/api/stats/a", "/api/stats/b", "/api/x"} {
+		s.Set(ctx, key, &Entry{}, time.Minute)
+	}
+
+	if n, _ := s.Purge(ctx, "/api/stats"); n != 2 {
+		t.Errorf("purged %d entries, want 2", n)
+	}
+	if _, ok, _ := s.Get(ctx, "/api/x"); !ok {
+		t.Error("entry outside the prefix purged")
+	}
+}
+
+func TestRememberServesStaleAndRevalidates(t *testing.T) {
+	ctx := context.Background()
+	c := New(NewMemoryStore(10))
+	policy := Policy{TTL: 20 * time.Millisecond, Stale: time.Minute}
+
+	var loads atomic.Int32
+	load := func(context.Context) (int32, error) { return loads.Add(1), nil }
+
+	if v, _ := Remember(ctx, c, "k", policy, load); v != 1 {
+		t.Fatalf("first load = %d, want 1", v)
+	}
+	if v, _ := Remember(ctx, c, "k", policy, load); v != 1 {
+		t.Errorf("fresh value = %d, want 1", v)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if v, _ := Remember(ctx, c, "k", policy, load); v != 1 {
+		t.Errorf("stale value = %d, want 1", v)
+	}
+	deadline := time.Now().Add(time.Second)
+	for loads.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if v, _ := Remember(ctx, c, "k", policy, load); v != 2 {
+		t.Errorf("revalidated value = %d, want 2", v)
+	}
+}
+
+func TestRememberCoalescesLoads(t *testing.T) {
+	c := New(NewMemoryStore(10))
+	policy := Policy{TTL: time.Minute}
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	// The first caller leaves before the load is done, the others must still
+	// get its result
+	firstCtx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := Remember(firstCtx, c, "k", policy, load)
+		firstErr <- err
+	}()
+	for loads.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 10)
+	errs := make([]error, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = Remember(context.Background(), c, "k", policy, load)
+		}(i)
+	}
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller error = %v, want %v", err, context.Canceled)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loads = %d, want 1", n)
+	}
+	for i := range results {
+		if errs[i] != nil || results[i] != 42 {
+			t.Errorf("waiter %d got (%d, %v), want (42, <nil>)", i, results[i], errs[i])
+		}
+	}
+}
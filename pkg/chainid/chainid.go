@@ -0,0 +1,81 @@
+package chainid
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/scalarorg/bitcoin-vault/go-utils/chain"
+)
+
+// Chain ids are stored as bitcoin|4 and evm|11155111, and some EVM tables
+// store the bare chain id 11155111. The canonical form always has the type.
+const evmPrefix = "evm|"
+
+var typeNames = map[chain.ChainType]string{
+	chain.ChainTypeBitcoin: "bitcoin",
+	chain.ChainTypeEVM:     "evm",
+	chain.ChainTypeSolana:  "solana",
+	chain.ChainTypeCosmos:  "cosmos",
+}
+
+// Parse parses a chain id in any of its stored forms, a bare number is an EVM
+// chain id
+func Parse(id string) (chain.ChainInfo, error) {
+	var info chain.ChainInfo
+	if isBare(id) {
+		id = evmPrefix + id
+	}
+	if err := info.FromString(id); err != nil {
+		return info, fmt.Errorf("invalid chain id %q: %w", id, err)
+	}
+	return info, nil
+}
+
+// Format returns the canonical form of a parsed chain id
+func Format(info chain.ChainInfo) string {
+	return typeNames[info.ChainType] + "|" + strconv.FormatUint(info.ChainID, 10)
+}
+
+// Normalize returns the canonical form of a chain id, or the id unchanged when
+// it cannot be parsed. The empty id stays empty so it can keep meaning every
+// chain in filters.
+func Normalize(id string) string {
+	if id == "" {
+		return id
+	}
+	info, err := Parse(id)
+	if err != nil {
+		return id
+	}
+	if _, ok := typeNames[info.ChainType]; !ok {
+		return id
+	}
+	return Format(info)
+}
+
+// Forms returns the forms a chain id may be stored in: its canonical form and,
+// for EVM chains, the bare chain id. Columns are filtered with
+// col = ANY(forms) rather than by comparing SQL(col), so that their indexes
+// are used. The empty id has no form.
+func Forms(id string) []string {
+	if id == "" {
+		return nil
+	}
+	normalized := Normalize(id)
+	if bare, ok := strings.CutPrefix(normalized, evmPrefix); ok {
+		return []string{normalized, bare}
+	}
+	return []string{normalized}
+}
+
+// SQL returns the SQL expression of the canonical form of the chain id stored
+// in column, to compare and group chains whichever form they are stored in
+func SQL(column string) string {
+	return fmt.Sprintf("(CASE WHEN %[1]s ~ '^[0-9]+$' THEN '%[2]s' || %[1]s ELSE %[1]s END)", column, evmPrefix)
+}
+
+func isBare(id string) bool {
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,84 @@
+package chainid
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/scalarorg/bitcoin-vault/go-utils/chain"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    chain.ChainInfo
+		wantErr bool
+	}{
+		{id: "bitcoin|4", want: chain.ChainInfo{ChainType: chain.ChainTypeBitcoin, ChainID: 4}},
+		{id: "evm|11155111", want: chain.ChainInfo{ChainType: chain.ChainTypeEVM, ChainID: 11155111}},
+		{id: "11155111", want: chain.ChainInfo{ChainType: chain.ChainTypeEVM, ChainID: 11155111}},
+		{id: "", wantErr: true},
+		{id: "evm|abc", wantErr: true},
+		{id: "sepolia", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		info chain.ChainInfo
+		want string
+	}{
+		{chain.ChainInfo{ChainType: chain.ChainTypeBitcoin, ChainID: 4}, "bitcoin|4"},
+		{chain.ChainInfo{ChainType: chain.ChainTypeEVM, ChainID: 11155111}, "evm|11155111"},
+	}
+	for _, tt := range tests {
+		if got := Format(tt.info); got != tt.want {
+			t.Errorf("Format(%+v) = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"bitcoin|4", "bitcoin|4"},
+		{"evm|11155111", "evm|11155111"},
+		{"11155111", "evm|11155111"},
+		{"", ""},
+		{"sepolia", "sepolia"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.id); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestForms(t *testing.T) {
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"bitcoin|4", []string{"bitcoin|4"}},
+		{"evm|11155111", []string{"evm|11155111", "11155111"}},
+		{"11155111", []string{"evm|11155111", "11155111"}},
+		{"", nil},
+		{"sepolia", []string{"sepolia"}},
+	}
+	for _, tt := range tests {
+		if got := Forms(tt.id); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Forms(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
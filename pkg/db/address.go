@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/data-models/chains"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// AddressTx is a transaction of one of the addresses looked up, Address being
+// the one it matched
+type AddressTx struct {
+	Address string `gorm:"column:address"`
+	BaseCrossChainTxResult
+}
+
+// ListTxsByAddresses returns the latest transactions of a kind for each of
+// addresses, at most first per address. Addresses are lowercased EVM addresses
+// or bitcoin script pubkeys, they match the sender or the receiver.
+func ListTxsByAddresses(ctx context.Context, kind CrossChainTx, addresses []string, first int) ([]AddressTx, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	var query *gorm.DB
+	// Redeems are only looked up by sender, the receiver is a bitcoin address
+	match := "LOWER(txs.source_address) = k.address OR LOWER(txs.destination_address) = k.address"
+	switch kind {
+	case CrossChainTxBridge:
+		query = BuildVaultTxsBaseQuery(DB.Indexer, func(db *gorm.DB) {
+			db.Where("vt.chain = ?", config.Env.BITCOIN_CHAIN_ID).
+				Where("vt.staker_script_pubkey IN ? OR LOWER(vt.destination_recipient_address) IN ?", addresses, addresses)
+		})
+	case CrossChainTxTransfer:
+		query = BuildTokenSentsBaseQuery(DB.Indexer, func(db *gorm.DB) {
+			db.Where("ts.source_chain <> ?", config.Env.BITCOIN_CHAIN_ID).
+				Where("LOWER(ts.source_address) IN ? OR LOWER(ts.destination_address) IN ?", addresses, addresses)
+		})
+	case CrossChainTxRedeem:
+		query = BuildRedeemTxsBaseQuery(DB.Indexer, func(db *gorm.DB) {
+			db.Where("LOWER(ert.source_address) IN ?", addresses)
+		})
+		match = "LOWER(txs.source_address) = k.address"
+	default:
+		return nil, fmt.Errorf("invalid type %q", kind)
+	}
+
+	rawQuery := `
+	SELECT * FROM (
+		SELECT
+			txs.*,
+			k.address,
+			ROW_NUMBER() OVER (PARTITION BY k.address ORDER BY txs.block_number DESC) as address_row
+		FROM (?) txs
+		JOIN unnest(?::text[]) k(address) ON ` + match + `
+	) ranked
+	WHERE address_row <= ?
+	ORDER BY address_row ASC
+	`
+
+	var results []AddressTx
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, query, textArray(addresses), first).Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s txs by addresses: %w", kind, err)
+	}
+
+	for i, result := range results {
+		if result.ExecutedTxHash != "" {
+			results[i].Status = string(chains.TokenSentStatusSuccess)
+		}
+		if kind != CrossChainTxBridge {
+			continue
+		}
+		address, err := utils.ScriptPubKeyToAddress(result.SourceAddress, result.SourceChain)
+		if err != nil {
+			log.Warn().Err(err).Str("script", result.SourceAddress).Msg("failed to convert script pubkey to address")
+		} else {
+			results[i].SourceAddress = address.String()
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The API keys are owned by this service, their tables are created on the
+// indexer database by createServiceTables
+var serviceTables = []string{
+	`CREATE TABLE IF NOT EXISTS api_keys (
+		id BIGSERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		scopes TEXT[] NOT NULL DEFAULT '{}',
+		tier TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		expires_at TIMESTAMPTZ,
+		revoked_at TIMESTAMPTZ,
+		last_used_at TIMESTAMPTZ
+	)`,
+	`CREATE TABLE IF NOT EXISTS api_key_usages (
+		api_key_id BIGINT NOT NULL REFERENCES api_keys(id),
+		day DATE NOT NULL,
+		requests BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (api_key_id, day)
+	)`,
+}
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+type APIKey struct {
+	ID   uint64 `gorm:"column:id" json:"id"`
+	Name string `gorm:"column:name" json:"name"`
+	// Prefix is the start of the key, to recognize it without the secret
+	Prefix     string     `gorm:"column:prefix" json:"prefix"`
+	KeyHash    string     `gorm:"column:key_hash" json:"-"`
+	Scopes     []string   `gorm:"-" json:"scopes"`
+	RawScopes  string     `gorm:"column:scopes" json:"-"`
+	Tier       string     `gorm:"column:tier" json:"tier"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"created_at"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+}
+
+type APIKeyUsage struct {
+	Day      time.Time `gorm:"column:day" json:"day"`
+	Requests uint64    `gorm:"column:requests" json:"requests"`
+}
+
+const apiKeyColumns = `
+	id,
+	name,
+	prefix,
+	key_hash,
+	array_to_string(scopes, ',') as scopes,
+	tier,
+	created_at,
+	expires_at,
+	revoked_at,
+	last_used_at
+`
+
+func (k *APIKey) splitScopes() {
+	k.Scopes = []string{}
+	if k.RawScopes != "" {
+		k.Scopes = strings.Split(k.RawScopes, ",")
+	}
+}
+
+// CreateAPIKey stores a new key, only its hash is stored
+func CreateAPIKey(ctx context.Context, key *APIKey) (*APIKey, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	INSERT INTO api_keys (name, prefix, key_hash, scopes, tier, expires_at)
+	VALUES ($1, $2, $3, $4::text[], $5, $6)
+	RETURNING ` + apiKeyColumns
+
+	var created APIKey
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, key.Name, key.Prefix, key.KeyHash, textArray(key.Scopes), key.Tier, key.ExpiresAt).Scan(&created).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+	created.splitScopes()
+	return &created, nil
+}
+
+// GetAPIKeyByHash returns the key of a hash, revoked and expired keys included
+func GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	rawQuery := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	var keys []APIKey
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, keyHash).Scan(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api key: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, ErrAPIKeyNotFound
+	}
+	keys[0].splitScopes()
+	return &keys[0], nil
+}
+
+// ListAPIKeys returns every key, the latest first
+func ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rawQuery := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY id DESC`
+
+	var keys []APIKey
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery).Scan(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api keys: %w", err)
+	}
+	for i := range keys {
+		keys[i].splitScopes()
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key, revoking it again keeps its first revocation time
+func RevokeAPIKey(ctx context.Context, id uint64) (*APIKey, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	UPDATE api_keys
+	SET revoked_at = COALESCE(revoked_at, now())
+	WHERE id = $1
+	RETURNING ` + apiKeyColumns
+
+	var keys []APIKey
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, id).Scan(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, ErrAPIKeyNotFound
+	}
+	keys[0].splitScopes()
+	return &keys[0], nil
+}
+
+// AddAPIKeyUsage adds the requests counted since the last call to the usage of
+// the day of each key
+func AddAPIKeyUsage(ctx context.Context, day time.Time, requests map[uint64]uint64) error {
+	if len(requests) == 0 {
+		return nil
+	}
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return DB.Indexer.WithContext(ctxWithTimeout).Transaction(func(tx *gorm.DB) error {
+		for id, count := range requests {
+			err := tx.Exec(`
+			INSERT INTO api_key_usages (api_key_id, day, requests)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (api_key_id, day) DO UPDATE SET requests = api_key_usages.requests + excluded.requests
+			`, id, day.Format(time.DateOnly), count).Error
+			if err != nil {
+				return fmt.Errorf("failed to add api key usage: %w", err)
+			}
+			err = tx.Exec(`UPDATE api_keys SET last_used_at = now() WHERE id = $1`, id).Error
+			if err != nil {
+				return fmt.Errorf("failed to update api key last use: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetAPIKeyUsage returns the daily requests of a key over the last days
+func GetAPIKeyUsage(ctx context.Context, id uint64, days int) ([]APIKeyUsage, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	SELECT day, requests
+	FROM api_key_usages
+	WHERE api_key_id = $1
+		AND day > CURRENT_DATE - $2::int
+	ORDER BY day DESC
+	`
+
+	var usage []APIKeyUsage
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, id, days).Scan(&usage).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch api key usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+)
+
+// ChainStats is the outgoing activity of a chain: bridges out of bitcoin,
+// transfers and redeems out of EVM chains
+type ChainStats struct {
+	Chain  string `gorm:"column:chain"`
+	Txs    uint64 `gorm:"column:txs"`
+	Volume uint64 `gorm:"column:volume"`
+	Users  uint64 `gorm:"column:users"`
+}
+
+// GetChainStats returns the stats of the given chains, or of every chain with
+// activity when chains is empty
+func GetChainStats(ctx context.Context, chains []string) ([]ChainStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH events AS (
+		SELECT
+			` + chainid.SQL("chain") + ` as chain,
+			staker_script_pubkey as user_id,
+			amount
+		FROM vault_transactions
+		WHERE amount > 0
+			AND (cardinality($1::text[]) = 0 OR chain = ANY($1::text[]))
+		UNION ALL
+		SELECT
+			` + chainid.SQL("source_chain") + ` as chain,
+			source_address as user_id,
+			amount
+		FROM token_sents
+		WHERE source_chain <> $2::text
+			AND amount > 0
+			AND (cardinality($1::text[]) = 0 OR source_chain = ANY($1::text[]))
+		UNION ALL
+		SELECT
+			` + chainid.SQL("source_chain") + ` as chain,
+			source_address as user_id,
+			amount
+		FROM evm_redeem_txes
+		WHERE amount > 0
+			AND (cardinality($1::text[]) = 0 OR source_chain = ANY($1::text[]))
+	)
+	SELECT
+		chain,
+		COUNT(*) as txs,
+		SUM(amount) as volume,
+		COUNT(DISTINCT user_id) as users
+	FROM events
+	GROUP BY chain
+	ORDER BY txs DESC
+	`
+
+	var stats []ChainStats
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, textArray(chainForms(chains)), config.Env.BITCOIN_CHAIN_ID).Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain stats: %w", err)
+	}
+	return stats, nil
+}
+
+// chainForms lists every form the chains may be stored in
+func chainForms(chains []string) []string {
+	forms := make([]string, 0, 2*len(chains))
+	for _, chain := range chains {
+		forms = append(forms, chainid.Forms(chain)...)
+	}
+	return forms
+}
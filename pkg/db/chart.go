@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+)
+
+const (
+	ChartTxs         = "txs"
+	ChartVolumes     = "volumes"
+	ChartActiveUsers = "active-users"
+	ChartNewUsers    = "new-users"
+)
+
+// ChartFilter narrows a chart to the transactions of a path, an empty chain
+// matches every chain. GroupBy splits the chart into one series per source
+// chain, path or asset.
+type ChartFilter struct {
+	SourceChain      string
+	DestinationChain string
+	GroupBy          string
+}
+
+// ChartPoint is the value of one series in one bucket. The group fields not
+// selected by ChartFilter.GroupBy are empty.
+type ChartPoint struct {
+	BucketTime       time.Time `gorm:"column:bucket_time"`
+	SourceChain      string    `gorm:"column:group_source"`
+	DestinationChain string    `gorm:"column:group_destination"`
+	Asset            string    `gorm:"column:group_asset"`
+	Value            uint64    `gorm:"column:value"`
+}
+
+// chartEvents selects the transactions of the charts with the group fields of
+// $6. Token transfers only count as transactions, volumes and users are those
+// of the vault like the global charts. The chains are filtered on the stored
+// forms $2 and $3 in each branch so that the indexes of the columns are used.
+var chartEvents = `
+	WITH events AS (
+		SELECT
+			` + chainid.SQL("chain") + ` as source_chain,
+			` + chainid.SQL("destination_chain") + ` as destination_chain,
+			'BTC' as asset,
+			staker_script_pubkey as user_id,
+			amount,
+			timestamp as block_time
+		FROM vault_transactions
+		WHERE timestamp IS NOT NULL
+			AND (cardinality($2::text[]) = 0 OR chain = ANY($2::text[]))
+			AND (cardinality($3::text[]) = 0 OR destination_chain = ANY($3::text[]))
+		UNION ALL
+		SELECT
+			` + chainid.SQL("ccwt.source_chain") + ` as source_chain,
+			` + chainid.SQL("ccwt.destination_chain") + ` as destination_chain,
+			COALESCE(ccwt.symbol, '') as asset,
+			ccwt.source_address as user_id,
+			ccwt.amount,
+			bh.block_time
+		FROM contract_call_with_tokens ccwt
+		INNER JOIN block_headers bh ON ccwt.source_chain = bh.chain
+			AND ccwt.block_number = bh.block_number
+		WHERE bh.block_time IS NOT NULL
+			AND $1::text = 'txs'
+			AND (cardinality($2::text[]) = 0 OR ccwt.source_chain = ANY($2::text[]))
+			AND (cardinality($3::text[]) = 0 OR ccwt.destination_chain = ANY($3::text[]))
+	),
+	filtered AS (
+		SELECT
+			CASE WHEN $6::text IN ('chain', 'path') THEN source_chain ELSE '' END as group_source,
+			CASE WHEN $6::text = 'path' THEN destination_chain ELSE '' END as group_destination,
+			CASE WHEN $6::text = 'asset' THEN asset ELSE '' END as group_asset,
+			user_id,
+			amount,
+			block_time
+		FROM events
+	),
+	first_transactions AS (
+		SELECT
+			group_source,
+			group_destination,
+			group_asset,
+			user_id,
+			MIN(block_time) as block_time
+		FROM filtered
+		WHERE user_id IS NOT NULL
+			AND user_id != ''
+		GROUP BY group_source, group_destination, group_asset, user_id
+	)
+`
+
+// chartSources are the rows and value of each chart
+var chartSources = map[string]struct {
+	from  string
+	where string
+	value string
+}{
+	ChartTxs:         {"filtered", "TRUE", "COUNT(*)"},
+	ChartVolumes:     {"filtered", "amount > 0", "SUM(amount)"},
+	ChartActiveUsers: {"filtered", "user_id IS NOT NULL AND user_id != ''", "COUNT(DISTINCT user_id)"},
+	ChartNewUsers:    {"first_transactions", "TRUE", "COUNT(*)"},
+}
+
+// GetChartPoints returns the last limit buckets of a chart, cut in the tz time
+// zone, ordered by time. Every series shares the same buckets.
+func GetChartPoints(ctx context.Context, chart, timeBucket, tz string, limit int, filter ChartFilter) ([]ChartPoint, error) {
+	if !validateTimeBucketInterval(timeBucket) {
+		return nil, fmt.Errorf("invalid bucket name")
+	}
+	source, ok := chartSources[chart]
+	if !ok {
+		return nil, fmt.Errorf("invalid chart name")
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rawQuery := chartEvents + `,
+	series AS (
+		SELECT
+			date_trunc($4, to_timestamp(block_time) AT TIME ZONE $5) AT TIME ZONE $5 as bucket_time,
+			group_source,
+			group_destination,
+			group_asset,
+			` + source.value + ` as value
+		FROM ` + source.from + `
+		WHERE ` + source.where + `
+		GROUP BY 1, 2, 3, 4
+	),
+	ranked AS (
+		SELECT
+			*,
+			DENSE_RANK() OVER (ORDER BY bucket_time DESC) as bucket_rank
+		FROM series
+	)
+	SELECT bucket_time, group_source, group_destination, group_asset, value
+	FROM ranked
+	WHERE bucket_rank <= $7
+	ORDER BY bucket_time ASC
+	`
+
+	var points []ChartPoint
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, chart, textArray(chainid.Forms(filter.SourceChain)), textArray(chainid.Forms(filter.DestinationChain)), timeBucket, tz, filter.GroupBy, limit).Scan(&points).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s chart: %w", chart, err)
+	}
+	return points, nil
+}
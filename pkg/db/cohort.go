@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scalarorg/scalar-service/config"
+)
+
+type CohortActivity struct {
+	CohortTime time.Time `gorm:"column:cohort_time"`
+	BucketTime time.Time `gorm:"column:bucket_time"`
+	Users      uint64    `gorm:"column:users"`
+}
+
+// GetCohortActivity groups users by the bucket of their first vault transaction
+// or token sent and counts how many of each cohort are active in every bucket
+// since. Users are compared case-insensitively and transfers out of bitcoin
+// are already counted as vault transactions. Buckets are cut in the tz time
+// zone. Only the cohorts of the last limit buckets are returned.
+func GetCohortActivity(ctx context.Context, timeBucket, tz string, limit int) ([]CohortActivity, error) {
+	if !validateTimeBucketInterval(timeBucket) {
+		return nil, fmt.Errorf("invalid bucket name")
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH activity AS (
+		SELECT
+			lower(staker_script_pubkey) as user_id,
+			date_trunc($1, to_timestamp(timestamp) AT TIME ZONE $4) AT TIME ZONE $4 as bucket_time
+		FROM vault_transactions
+		WHERE staker_script_pubkey IS NOT NULL
+			AND staker_script_pubkey != ''
+			AND timestamp IS NOT NULL
+		UNION
+		SELECT
+			lower(source_address) as user_id,
+			date_trunc($1, to_timestamp(block_time) AT TIME ZONE $4) AT TIME ZONE $4 as bucket_time
+		FROM token_sents
+		WHERE source_chain <> $5::text
+			AND source_address IS NOT NULL
+			AND source_address != ''
+			AND block_time IS NOT NULL
+	),
+	first_transactions AS (
+		SELECT
+			user_id,
+			MIN(bucket_time) as cohort_time
+		FROM activity
+		GROUP BY user_id
+	)
+	SELECT
+		ft.cohort_time,
+		a.bucket_time,
+		COUNT(DISTINCT a.user_id) as users
+	FROM activity a
+	JOIN first_transactions ft ON a.user_id = ft.user_id
+	WHERE ft.cohort_time > (date_trunc($1, now() AT TIME ZONE $4) AT TIME ZONE $4) - ($2::int * $3::interval)
+	GROUP BY ft.cohort_time, a.bucket_time
+	ORDER BY ft.cohort_time ASC, a.bucket_time ASC
+	`
+
+	var activity []CohortActivity
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, timeBucket, limit, getTimeBucketInterval(timeBucket), tz, config.Env.BITCOIN_CHAIN_ID).Scan(&activity).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cohort activity: %w", err)
+	}
+	return activity, nil
+}
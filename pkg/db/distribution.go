@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+)
+
+// DistributionFilter narrows the amounts of a distribution to one kind, source
+// chain or asset, empty fields match everything. Edges are the ascending bucket
+// boundaries, when empty amounts are bucketed by power of ten.
+type DistributionFilter struct {
+	Kind  CrossChainTx
+	Chain string
+	Asset string
+	Edges []uint64
+}
+
+type AmountBucket struct {
+	Bucket int    `gorm:"column:bucket"`
+	Count  uint64 `gorm:"column:count"`
+	Amount uint64 `gorm:"column:amount"`
+}
+
+type AmountPercentiles struct {
+	Count uint64  `gorm:"column:count"`
+	Min   uint64  `gorm:"column:min"`
+	Max   uint64  `gorm:"column:max"`
+	Mean  float64 `gorm:"column:mean"`
+	P25   float64 `gorm:"column:p25"`
+	P50   float64 `gorm:"column:p50"`
+	P75   float64 `gorm:"column:p75"`
+	P90   float64 `gorm:"column:p90"`
+	P95   float64 `gorm:"column:p95"`
+	P99   float64 `gorm:"column:p99"`
+}
+
+// amountEvents selects the filtered amounts of bridges, transfers and redeems.
+// Transfers out of bitcoin are already counted as bridges. The filters are
+// applied on the stored columns of each branch so that their indexes are used.
+var amountEvents = `
+	WITH amounts AS (
+		SELECT amount
+		FROM vault_transactions
+		WHERE amount > 0
+			AND $1::text IN ('', 'bridge')
+			AND (cardinality($2::text[]) = 0 OR chain = ANY($2::text[]))
+			AND $3::text IN ('', 'BTC')
+		UNION ALL
+		SELECT amount
+		FROM token_sents
+		WHERE source_chain <> $4::text
+			AND amount > 0
+			AND $1::text IN ('', 'transfer')
+			AND (cardinality($2::text[]) = 0 OR source_chain = ANY($2::text[]))
+			AND ($3::text = '' OR symbol = $3::text)
+		UNION ALL
+		SELECT amount
+		FROM evm_redeem_txes
+		WHERE amount > 0
+			AND $1::text IN ('', 'redeem')
+			AND (cardinality($2::text[]) = 0 OR source_chain = ANY($2::text[]))
+			AND ($3::text = '' OR symbol = $3::text)
+	)
+`
+
+// GetAmountHistogram counts the amounts falling in each bucket. With edges the
+// bucket is the width_bucket index, 0 being below the first edge and len(edges)
+// at or above the last, otherwise it is the floor of the amount's log10.
+func GetAmountHistogram(ctx context.Context, filter DistributionFilter) ([]AmountBucket, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rawQuery := amountEvents + `
+	SELECT
+		CASE WHEN cardinality($5::numeric[]) > 0
+			THEN width_bucket(amount::numeric, $5::numeric[])
+			ELSE floor(log(amount::numeric))::int
+		END as bucket,
+		COUNT(*) as count,
+		SUM(amount) as amount
+	FROM amounts
+	GROUP BY bucket
+	ORDER BY bucket ASC
+	`
+
+	var buckets []AmountBucket
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, append(filter.args(), filter.edges())...).Scan(&buckets).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch amount histogram: %w", err)
+	}
+	return buckets, nil
+}
+
+func GetAmountPercentiles(ctx context.Context, filter DistributionFilter) (*AmountPercentiles, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rawQuery := amountEvents + `
+	SELECT
+		COUNT(*) as count,
+		COALESCE(MIN(amount), 0) as min,
+		COALESCE(MAX(amount), 0) as max,
+		COALESCE(AVG(amount), 0) as mean,
+		COALESCE(percentile_cont(0.25) WITHIN GROUP (ORDER BY amount), 0) as p25,
+		COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY amount), 0) as p50,
+		COALESCE(percentile_cont(0.75) WITHIN GROUP (ORDER BY amount), 0) as p75,
+		COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY amount), 0) as p90,
+		COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY amount), 0) as p95,
+		COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY amount), 0) as p99
+	FROM amounts
+	`
+
+	var percentiles AmountPercentiles
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, filter.args()...).Scan(&percentiles).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch amount percentiles: %w", err)
+	}
+	return &percentiles, nil
+}
+
+// args binds the filter to the placeholders of amountEvents
+func (f DistributionFilter) args() []any {
+	return []any{string(f.Kind), textArray(chainid.Forms(f.Chain)), f.Asset, config.Env.BITCOIN_CHAIN_ID}
+}
+
+// edges formats the bucket edges as a postgres array literal
+func (f DistributionFilter) edges() string {
+	edges := make([]string, len(f.Edges))
+	for i, edge := range f.Edges {
+		edges[i] = strconv.FormatUint(edge, 10)
+	}
+	return "{" + strings.Join(edges, ",") + "}"
+}
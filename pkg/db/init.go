@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -25,6 +26,7 @@ func Init() {
 
 	// Create optimized indexes for better query performance
 	createOptimizedIndexes()
+	createServiceTables()
 }
 
 func Close() error {
@@ -148,6 +150,7 @@ func createOptimizedIndexes() {
 		`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_evm_redeem_txes_destination_chain ON evm_redeem_txes(destination_chain)`,
 		`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_evm_redeem_txes_custodian_group_uid ON evm_redeem_txes(custodian_group_uid)`,
 		`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_evm_redeem_txes_session_sequence ON evm_redeem_txes(session_sequence)`,
+		`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_evm_redeem_txes_source_chain_block_number ON evm_redeem_txes(source_chain, block_number)`,
 
 		// BTC redeem txes indexes
 		`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_btc_redeem_txes_custodian_group_uid ON btc_redeem_txes(custodian_group_uid)`,
@@ -165,3 +168,27 @@ func createOptimizedIndexes() {
 	log.Info().Msg("Database indexes optimization completed")
 }
 
+// serviceTablesReady is false until the tables owned by this service are
+// created, the features using them are disabled otherwise
+var serviceTablesReady atomic.Bool
+
+// ServiceTablesReady reports whether the tables owned by this service exist
+func ServiceTablesReady() bool {
+	return serviceTablesReady.Load()
+}
+
+// createServiceTables creates the tables owned by this service. The indexer
+// database may be read only for this service, a failure is logged and leaves
+// the tables disabled rather than stopping the server.
+func createServiceTables() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for _, tableSQL := range serviceTables {
+		if err := DB.Indexer.WithContext(ctx).Exec(tableSQL).Error; err != nil {
+			log.Error().Err(err).Msg("Failed to create service tables on indexer DB, API keys are disabled")
+			return
+		}
+	}
+	serviceTablesReady.Store(true)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/scalarorg/bitcoin-vault/go-utils/chain"
 	"github.com/scalarorg/data-models/chains"
 	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/chainid"
 )
 
 type ExpectedCrossChainDocument interface {
@@ -35,6 +36,13 @@ type CrossChainDocument struct {
 	Destination *DestinationDocument `json:"destination"`
 }
 
+// Executed reports whether the transaction was executed on its destination
+// chain, after which the document does not change
+func (d *CrossChainDocument) Executed() bool {
+	return d.Destination != nil && d.Destination.BaseDocument != nil &&
+		d.Destination.Status == string(chains.TokenSentStatusSuccess)
+}
+
 type CrossChainAsset struct {
 	Name     string `json:"name"`
 	Symbol   string `json:"symbol"`
@@ -55,6 +63,9 @@ type BaseDocument struct {
 	Fee             string          `json:"fee"`
 	CrossChainAsset CrossChainAsset `json:"asset"`
 	CreatedAt       uint64          `json:"created_at"`
+
+	// USD value of the transfer at its source block time, only set on request
+	USD *float64 `json:"usd,omitempty"`
 }
 
 type SourceDocument struct {
@@ -115,18 +126,21 @@ func (b *BaseCrossChainTxResult) GetStatus() string {
 	return b.Status
 }
 
-func (b *BaseCrossChainTxResult) GetSource() *SourceDocument {
-	var c = &chain.ChainInfo{}
-	var name = ""
-	err := c.FromString(b.SourceChain)
+// ChainName returns the display name of a chain id, or the id itself if it is
+// not a known chain
+func ChainName(id string) string {
+	c, err := chainid.Parse(id)
 	if err != nil {
-		name = b.SourceChain
-	} else {
-		name = chain.GetDisplayedName(*c)
+		return id
 	}
+	return chain.GetDisplayedName(c)
+}
+
+func (b *BaseCrossChainTxResult) GetSource() *SourceDocument {
+	name := ChainName(b.SourceChain)
 	return &SourceDocument{
 		BaseDocument: &BaseDocument{
-			Chain:     b.SourceChain,
+			Chain:     chainid.Normalize(b.SourceChain),
 			ChainName: name,
 			TxHash:    b.TxHash,
 			Status:    b.Status,
@@ -151,14 +165,7 @@ func (b *BaseCrossChainTxResult) GetSource() *SourceDocument {
 }
 
 func (b *BaseCrossChainTxResult) GetDestination() *DestinationDocument {
-	var c = &chain.ChainInfo{}
-	var name = ""
-	err := c.FromString(b.DestinationChain)
-	if err != nil {
-		name = b.DestinationChain
-	} else {
-		name = chain.GetDisplayedName(*c)
-	}
+	name := ChainName(b.DestinationChain)
 
 	var status = chains.TokenSentStatusPending
 	if b.TxHash != "" && b.ExecutedTxHash != "" {
@@ -167,7 +174,7 @@ func (b *BaseCrossChainTxResult) GetDestination() *DestinationDocument {
 
 	return &DestinationDocument{
 		BaseDocument: &BaseDocument{
-			Chain:     b.DestinationChain,
+			Chain:     chainid.Normalize(b.DestinationChain),
 			ChainName: name,
 			TxHash:    b.ExecutedTxHash,
 
@@ -74,6 +74,23 @@ func BuildContractCallWithTokenBaseQuery(db *gorm.DB, extendWhereClause func(db
 	return query.Joins("LEFT JOIN command_executeds ce ON ccwtk.tx_hash = ce.command_id").Order("ccwtk.block_number DESC")
 }
 
+func BuildRedeemTxsBaseQuery(db *gorm.DB, extendWhereClause func(db *gorm.DB)) *gorm.DB {
+	query := db.Table("evm_redeem_txes ert").
+		Select(`
+            ert.*,
+            brt.tx_hash as command_id,
+            brt.tx_hash as executed_tx_hash,
+            brt.block_number as executed_block_number,
+            brt.custodian_group_uid as executed_address,
+			to_timestamp(dbh.block_time) as source_created_at,
+            to_timestamp(brt.block_time) as executed_created_at,
+			dbh.block_time AS block_time
+        `).Where("ert.destination_chain = ?", config.Env.BITCOIN_CHAIN_ID)
+	extendWhereClause(query)
+	return query.Joins("LEFT JOIN btc_redeem_txes brt ON ert.custodian_group_uid = brt.custodian_group_uid AND ert.session_sequence = brt.session_sequence").
+		Joins("LEFT JOIN block_headers dbh ON ert.source_chain = dbh.chain AND ert.block_number = dbh.block_number")
+}
+
 func AggregateCrossChainTxs(ctx context.Context, query *gorm.DB, size, offset int) ([]BaseCrossChainTxResult, int, error) {
 	var results []BaseCrossChainTxResult
 
@@ -194,19 +211,7 @@ func ListRedeemTxs(ctx context.Context, size, offset int) ([]BaseCrossChainTxRes
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 45*time.Second)
 	defer cancel()
 
-	query := DB.Indexer.Table("evm_redeem_txes ert").
-		Select(`
-            ert.*,
-            brt.tx_hash as command_id,
-            brt.tx_hash as executed_tx_hash,
-            brt.block_number as executed_block_number,
-            brt.custodian_group_uid as executed_address,
-			to_timestamp(dbh.block_time) as source_created_at,
-            to_timestamp(brt.block_time) as executed_created_at,
-			dbh.block_time AS block_time
-        `).Where("ert.destination_chain = ?", config.Env.BITCOIN_CHAIN_ID).
-		Joins("LEFT JOIN btc_redeem_txes brt ON ert.custodian_group_uid = brt.custodian_group_uid AND ert.session_sequence = brt.session_sequence").
-		Joins("LEFT JOIN block_headers dbh ON ert.source_chain = dbh.chain AND ert.block_number = dbh.block_number")
+	query := BuildRedeemTxsBaseQuery(DB.Indexer, func(db *gorm.DB) {})
 
 	return AggregateCrossChainTxs(ctxWithTimeout, query, size, offset)
 }
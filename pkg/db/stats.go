@@ -4,10 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"sync"
 	"time"
-
-	"github.com/rs/zerolog/log"
 )
 
 type Stats struct {
@@ -48,94 +45,6 @@ type Stats struct {
 // 	return result
 // }
 
-// Count transactions by time with optimized parallel queries
-func GetCommandStats(ctx context.Context, timeBucket string, limit int) ([]Stats, error) {
-	if !validateTimeBucketInterval(timeBucket) {
-		return nil, fmt.Errorf("invalid bucket name")
-	}
-	
-	// Add timeout to the context if not already set
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-	
-	wg := sync.WaitGroup{}
-	wg.Add(2)
-	var vaultTxStats []Stats
-	var vaultErr error
-	
-	go func() {
-		defer wg.Done()
-		// Optimized query with proper indexing
-		query := `
-			SELECT 
-				date_trunc($1, to_timestamp(timestamp)) as bucket_time, 
-				COUNT(*) as count
-			FROM vault_transactions
-			WHERE timestamp IS NOT NULL
-			GROUP BY bucket_time
-			ORDER BY bucket_time DESC
-			LIMIT $2
-		`
-		vaultErr = DB.Indexer.WithContext(ctxWithTimeout).Raw(query, timeBucket, limit).Scan(&vaultTxStats).Error
-		if vaultErr != nil {
-			log.Error().Err(vaultErr).Msg("failed to fetch vault_transactions stats")
-		}
-	}()
-	
-	var ccwtStats []Stats
-	var ccwtErr error
-	
-	go func() {
-		defer wg.Done()
-		// Optimized query with better JOIN performance
-		query := `
-			SELECT 
-				date_trunc($1, to_timestamp(bh.block_time)) as bucket_time, 
-				COUNT(*) as count
-			FROM contract_call_with_tokens ccwt 
-			INNER JOIN block_headers bh ON ccwt.source_chain = bh.chain 
-				AND ccwt.block_number = bh.block_number
-			WHERE bh.block_time IS NOT NULL
-			GROUP BY bucket_time
-			ORDER BY bucket_time DESC
-			LIMIT $2
-		`
-		ccwtErr = DB.Indexer.WithContext(ctxWithTimeout).Raw(query, timeBucket, limit).Scan(&ccwtStats).Error
-		if ccwtErr != nil {
-			log.Error().Err(ccwtErr).Msg("failed to fetch contract_call_with_tokens stats")
-		}
-	}()
-	
-	wg.Wait()
-	
-	// Handle errors
-	if vaultErr != nil && ccwtErr != nil {
-		return nil, fmt.Errorf("both queries failed: vault=%v, ccwt=%v", vaultErr, ccwtErr)
-	}
-	
-	// Merge and sort results efficiently
-	sort.Slice(vaultTxStats, func(i, j int) bool {
-		return vaultTxStats[i].BucketTime.Before(vaultTxStats[j].BucketTime)
-	})
-	sort.Slice(ccwtStats, func(i, j int) bool {
-		return ccwtStats[i].BucketTime.Before(ccwtStats[j].BucketTime)
-	})
-	
-	allStats := mergeSortedStats(vaultTxStats, ccwtStats, func(a, b Stats) int {
-		return a.BucketTime.Compare(b.BucketTime)
-	}, func(a, b Stats) Stats {
-		return Stats{
-			BucketTime: a.BucketTime,
-			Count:      a.Count + b.Count,
-		}
-	})
-	
-	if len(allStats) > limit {
-		return allStats[len(allStats)-limit:], nil
-	}
-	return allStats, nil
-}
-
 // func GetCommandStatsWithTimeScale(ctx context.Context, timeBucket string) ([]Stats, error) {
 // 	interval := getTimeBucketInterval(timeBucket)
 
@@ -160,8 +69,8 @@ type TokenSentStats struct {
 	NewUsers    uint64    `json:"new_users" gorm:"column:new_users"`
 }
 
-func GetStatsByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+func GetStatsByTimeBucket(ctx context.Context, timeBucket, tz string, limit int) ([]TokenSentStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 	
 	if !validateTimeBucketInterval(timeBucket) {
@@ -171,7 +80,7 @@ func GetStatsByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, error
 	// Optimized query with proper filtering and indexing
 	rawQuery := `
 	SELECT 
-		date_trunc($1, to_timestamp(vt.timestamp)) as bucket_time,
+		date_trunc($1, to_timestamp(vt.timestamp) AT TIME ZONE $3) AT TIME ZONE $3 as bucket_time,
 		SUM(amount) as total_amount,
 		COUNT(DISTINCT staker_script_pubkey) as active_users
 	FROM vault_transactions vt
@@ -184,7 +93,7 @@ func GetStatsByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, error
 	`
 	
 	var stats []TokenSentStats
-	err := DB.Indexer.WithContext(ctx).Raw(rawQuery, timeBucket, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, timeBucket, limit, tz).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch token stats: %w", err)
 	}
@@ -199,8 +108,8 @@ func GetStatsByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, error
 	return stats, nil
 }
 
-func GetVolumeByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+func GetVolumeByTimeBucket(ctx context.Context, timeBucket, tz string, limit int) ([]TokenSentStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 	
 	if !validateTimeBucketInterval(timeBucket) {
@@ -210,7 +119,7 @@ func GetVolumeByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, erro
 	// Optimized query with proper filtering
 	rawQuery := `
 	SELECT 
-		date_trunc($1, to_timestamp(vt.timestamp)) as bucket_time,
+		date_trunc($1, to_timestamp(vt.timestamp) AT TIME ZONE $3) AT TIME ZONE $3 as bucket_time,
 		SUM(amount) as total_amount
 	FROM vault_transactions vt
 	WHERE vt.timestamp IS NOT NULL
@@ -221,7 +130,7 @@ func GetVolumeByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, erro
 	`
 	
 	var stats []TokenSentStats
-	err := DB.Indexer.WithContext(ctx).Raw(rawQuery, timeBucket, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, timeBucket, limit, tz).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch volume stats: %w", err)
 	}
@@ -236,8 +145,8 @@ func GetVolumeByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, erro
 	return stats, nil
 }
 
-func GetActiveUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+func GetActiveUsersByTimeBucket(ctx context.Context, timeBucket, tz string, limit int) ([]TokenSentStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 	
 	if !validateTimeBucketInterval(timeBucket) {
@@ -247,7 +156,7 @@ func GetActiveUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats,
 	// Optimized query with proper filtering
 	rawQuery := `
 	SELECT 
-		date_trunc($1, to_timestamp(vt.timestamp)) as bucket_time,
+		date_trunc($1, to_timestamp(vt.timestamp) AT TIME ZONE $3) AT TIME ZONE $3 as bucket_time,
 		COUNT(DISTINCT staker_script_pubkey) as active_users
 	FROM vault_transactions vt
 	WHERE vt.timestamp IS NOT NULL
@@ -259,7 +168,7 @@ func GetActiveUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats,
 	`
 	
 	var stats []TokenSentStats
-	err := DB.Indexer.WithContext(ctx).Raw(rawQuery, timeBucket, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, timeBucket, limit, tz).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch active users stats: %w", err)
 	}
@@ -273,8 +182,8 @@ func GetActiveUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats,
 	}
 	return stats, nil
 }
-func GetNewUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func GetNewUsersByTimeBucket(ctx context.Context, timeBucket, tz string, limit int) ([]TokenSentStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 	
 	if !validateTimeBucketInterval(timeBucket) {
@@ -294,7 +203,7 @@ func GetNewUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, er
 		GROUP BY staker_script_pubkey
 	)
 	SELECT 
-		date_trunc($1, to_timestamp(ft.first_timestamp)) as bucket_time,
+		date_trunc($1, to_timestamp(ft.first_timestamp) AT TIME ZONE $3) AT TIME ZONE $3 as bucket_time,
 		COUNT(DISTINCT ft.staker_script_pubkey) as new_users
 	FROM first_transactions ft
 	GROUP BY bucket_time
@@ -303,7 +212,7 @@ func GetNewUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, er
 	`
 	
 	var stats []TokenSentStats
-	err := DB.Indexer.WithContext(ctx).Raw(rawQuery, timeBucket, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, timeBucket, limit, tz).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch new users stats: %w", err)
 	}
@@ -317,8 +226,8 @@ func GetNewUsersByTimeBucket(timeBucket string, limit int) ([]TokenSentStats, er
 	}
 	return stats, nil
 }
-func GetTokenStats(timeBucket string, limit int) ([]TokenSentStats, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+func GetTokenStats(ctx context.Context, timeBucket, tz string, limit int) ([]TokenSentStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
 	defer cancel()
 	
 	if !validateTimeBucketInterval(timeBucket) {
@@ -328,7 +237,7 @@ func GetTokenStats(timeBucket string, limit int) ([]TokenSentStats, error) {
 	// Optimized query with proper filtering and indexing
 	rawQuery := `
 	SELECT 
-		date_trunc($1, to_timestamp(ts.block_time)) as bucket_time,
+		date_trunc($1, to_timestamp(ts.block_time) AT TIME ZONE $3) AT TIME ZONE $3 as bucket_time,
 		COUNT(DISTINCT ts.source_address) as active_users,
 		COUNT(DISTINCT CASE WHEN ts.block_time = first_seen.first_time THEN ts.source_address ELSE NULL END) as new_users,
 		SUM(ts.amount) as total_amount
@@ -351,7 +260,7 @@ func GetTokenStats(timeBucket string, limit int) ([]TokenSentStats, error) {
 	`
 	
 	var stats []TokenSentStats
-	err := DB.Relayer.WithContext(ctx).Raw(rawQuery, timeBucket, limit).Scan(&stats).Error
+	err := DB.Relayer.WithContext(ctxWithTimeout).Raw(rawQuery, timeBucket, limit, tz).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch token stats: %w", err)
 	}
@@ -366,8 +275,84 @@ func GetTokenStats(timeBucket string, limit int) ([]TokenSentStats, error) {
 	return stats, nil
 }
 
-func GetTotalTxs() (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// GetDailyBridgedVolumes returns the bridged volume per UTC day since the given
+// unix time, on chain or on every chain when chain is empty
+func GetDailyBridgedVolumes(ctx context.Context, chain string, since int64) ([]TokenSentStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	SELECT
+		date_trunc('day', to_timestamp(vt.timestamp)) as bucket_time,
+		SUM(amount) as total_amount
+	FROM vault_transactions vt
+	WHERE vt.timestamp IS NOT NULL
+		AND vt.timestamp >= $1
+		AND vt.amount > 0
+		AND ($2::text = '' OR vt.chain = $2::text)
+	GROUP BY bucket_time
+	ORDER BY bucket_time ASC
+	`
+
+	var stats []TokenSentStats
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, since, chain).Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch daily volumes: %w", err)
+	}
+	return stats, nil
+}
+
+type WindowStats struct {
+	Txs         int64 `gorm:"column:txs"`
+	Volume      int64 `gorm:"column:volume"`
+	ActiveUsers int64 `gorm:"column:active_users"`
+	NewUsers    int64 `gorm:"column:new_users"`
+}
+
+// GetWindowStats counts txs, volume, active and new users bridged from chain
+// between the unix times from (inclusive) and to (exclusive)
+func GetWindowStats(ctx context.Context, chain string, from, to int64) (*WindowStats, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH window_txs AS (
+		SELECT staker_script_pubkey, amount
+		FROM vault_transactions
+		WHERE chain = $1
+			AND timestamp >= $2
+			AND timestamp < $3
+	),
+	first_transactions AS (
+		SELECT
+			staker_script_pubkey,
+			MIN(timestamp) as first_timestamp
+		FROM vault_transactions
+		WHERE chain = $1
+			AND staker_script_pubkey IS NOT NULL
+			AND staker_script_pubkey != ''
+			AND timestamp IS NOT NULL
+		GROUP BY staker_script_pubkey
+	)
+	SELECT
+		(SELECT COUNT(*) FROM window_txs) as txs,
+		(SELECT COALESCE(SUM(amount), 0) FROM window_txs WHERE amount > 0) as volume,
+		(SELECT COUNT(DISTINCT staker_script_pubkey) FROM window_txs
+			WHERE staker_script_pubkey IS NOT NULL AND staker_script_pubkey != '') as active_users,
+		(SELECT COUNT(*) FROM first_transactions
+			WHERE first_timestamp >= $2 AND first_timestamp < $3) as new_users
+	`
+
+	var stats WindowStats
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, chain, from, to).Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch window stats: %w", err)
+	}
+	return &stats, nil
+}
+
+func GetTotalTxs(ctx context.Context) (int64, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	
 	var totalTxs int64
@@ -378,14 +363,14 @@ func GetTotalTxs() (int64, error) {
 		FROM vault_transactions
 		WHERE timestamp IS NOT NULL
 	`
-	err := DB.Indexer.WithContext(ctx).Raw(query).Scan(&totalTxs).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query).Scan(&totalTxs).Error
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch total txs: %w", err)
 	}
 	return totalTxs, nil
 }
-func GetTotalBridgedVolumes(chain string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func GetTotalBridgedVolumes(ctx context.Context, chain string) (int64, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	
 	var totalVolumes int64
@@ -398,15 +383,15 @@ func GetTotalBridgedVolumes(chain string) (int64, error) {
 			AND amount > 0
 			AND timestamp IS NOT NULL
 	`
-	err := DB.Indexer.WithContext(ctx).Raw(query, chain).Scan(&totalVolumes).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, chain).Scan(&totalVolumes).Error
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch total volumes: %w", err)
 	}
 	return totalVolumes, nil
 }
 
-func GetTotalUsers() (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func GetTotalUsers(ctx context.Context) (int64, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	
 	var totalUsers int64
@@ -418,7 +403,7 @@ func GetTotalUsers() (int64, error) {
 		WHERE staker_script_pubkey IS NOT NULL
 			AND staker_script_pubkey != ''
 	`
-	err := DB.Indexer.WithContext(ctx).Raw(query).Scan(&totalUsers).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query).Scan(&totalUsers).Error
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch total users: %w", err)
 	}
@@ -0,0 +1,227 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+)
+
+type LatestBlock struct {
+	Chain       string `gorm:"column:chain"`
+	BlockNumber uint64 `gorm:"column:block_number"`
+	BlockTime   int64  `gorm:"column:block_time"`
+}
+
+type PendingPath struct {
+	SourceChain      string `gorm:"column:source_chain"`
+	DestinationChain string `gorm:"column:destination_chain"`
+	Count            uint64 `gorm:"column:count"`
+	Amount           uint64 `gorm:"column:amount"`
+}
+
+type RedeemBacklog struct {
+	Chain  string `gorm:"column:chain"`
+	Count  uint64 `gorm:"column:count"`
+	Amount uint64 `gorm:"column:amount"`
+}
+
+type SentVolume struct {
+	Chain  string `gorm:"column:chain"`
+	Asset  string `gorm:"column:asset"`
+	Amount uint64 `gorm:"column:amount"`
+}
+
+type LatestTx struct {
+	Kind      CrossChainTx `gorm:"column:kind"`
+	BlockTime int64        `gorm:"column:block_time"`
+}
+
+// GetLatestBlocks returns the latest block indexed on each chain
+func GetLatestBlocks(ctx context.Context) ([]LatestBlock, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	SELECT DISTINCT ON (chain)
+		chain,
+		block_number,
+		block_time
+	FROM block_headers
+	WHERE block_time IS NOT NULL
+	ORDER BY chain, block_number DESC
+	`
+
+	var blocks []LatestBlock
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery).Scan(&blocks).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest blocks: %w", err)
+	}
+	return blocks, nil
+}
+
+// GetPendingTransfers counts the bridges and transfers per path sent since the
+// unix time since that have not been executed on their destination chain yet
+func GetPendingTransfers(ctx context.Context, since int64) ([]PendingPath, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH pending AS (
+		SELECT
+			vt.chain as source_chain,
+			vt.destination_chain,
+			vt.amount
+		FROM vault_transactions vt
+		LEFT JOIN command_executeds ce ON vt.tx_hash = ce.command_id
+		WHERE ce.command_id IS NULL
+			AND vt.amount > 0
+			AND vt.timestamp >= $2::bigint
+		UNION ALL
+		SELECT
+			ts.source_chain,
+			ts.destination_chain,
+			ts.amount
+		FROM token_sents ts
+		LEFT JOIN command_executeds ce ON ts.tx_hash = ce.command_id
+		WHERE ce.command_id IS NULL
+			AND ts.source_chain <> $1
+			AND ts.amount > 0
+			AND ts.block_time >= $2::bigint
+	)
+	SELECT
+		` + chainid.SQL("source_chain") + ` as source_chain,
+		` + chainid.SQL("destination_chain") + ` as destination_chain,
+		COUNT(*) as count,
+		SUM(amount) as amount
+	FROM pending
+	GROUP BY 1, 2
+	`
+
+	var paths []PendingPath
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, config.Env.BITCOIN_CHAIN_ID, since).Scan(&paths).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending transfers: %w", err)
+	}
+
+	return paths, nil
+}
+
+// GetRedeemBacklog counts the redeems requested on each EVM chain since the
+// unix time since that have no bitcoin redeem transaction yet. The redeems
+// whose block is not indexed yet are not counted.
+func GetRedeemBacklog(ctx context.Context, since int64) ([]RedeemBacklog, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	SELECT
+		` + chainid.SQL("ert.source_chain") + ` as chain,
+		COUNT(*) as count,
+		COALESCE(SUM(ert.amount), 0) as amount
+	FROM block_headers dbh
+	JOIN evm_redeem_txes ert ON ert.source_chain = dbh.chain AND ert.block_number = dbh.block_number
+	LEFT JOIN btc_redeem_txes brt ON ert.custodian_group_uid = brt.custodian_group_uid AND ert.session_sequence = brt.session_sequence
+	WHERE dbh.block_time >= $2::bigint
+		AND ert.destination_chain = $1
+		AND brt.tx_hash IS NULL
+	GROUP BY 1
+	`
+
+	var backlog []RedeemBacklog
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, config.Env.BITCOIN_CHAIN_ID, since).Scan(&backlog).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch redeem backlog: %w", err)
+	}
+	return backlog, nil
+}
+
+// GetLatestTxs returns the block time of the latest transaction of each kind
+// since the unix time since, 0 when there is none
+func GetLatestTxs(ctx context.Context, since int64) ([]LatestTx, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	SELECT 'bridge' as kind, COALESCE(MAX(timestamp), 0) as block_time
+	FROM vault_transactions
+	WHERE timestamp >= $2::bigint
+	UNION ALL
+	SELECT 'transfer' as kind, COALESCE(MAX(block_time), 0) as block_time
+	FROM token_sents
+	WHERE source_chain <> $1
+		AND block_time >= $2::bigint
+	UNION ALL
+	SELECT 'redeem' as kind, COALESCE(MAX(dbh.block_time), 0) as block_time
+	FROM block_headers dbh
+	JOIN evm_redeem_txes ert ON ert.source_chain = dbh.chain AND ert.block_number = dbh.block_number
+	WHERE dbh.block_time >= $2::bigint
+	`
+
+	var txs []LatestTx
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, config.Env.BITCOIN_CHAIN_ID, since).Scan(&txs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest txs: %w", err)
+	}
+	return txs, nil
+}
+
+// GetSentVolume sums the bridges and transfers sent out of each chain per
+// asset, over the transactions with a block time in [from, to)
+func GetSentVolume(ctx context.Context, from int64, to int64) ([]SentVolume, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH sent AS (
+		SELECT chain as source_chain, 'BTC' as asset, amount
+		FROM vault_transactions
+		WHERE amount > 0
+			AND timestamp >= $2::bigint
+			AND timestamp < $3::bigint
+		UNION ALL
+		SELECT source_chain, COALESCE(symbol, '') as asset, amount
+		FROM token_sents
+		WHERE source_chain <> $1
+			AND amount > 0
+			AND block_time >= $2::bigint
+			AND block_time < $3::bigint
+	)
+	SELECT
+		` + chainid.SQL("source_chain") + ` as chain,
+		asset,
+		SUM(amount) as amount
+	FROM sent
+	GROUP BY 1, 2
+	`
+
+	var volumes []SentVolume
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, config.Env.BITCOIN_CHAIN_ID, from, to).Scan(&volumes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sent volume: %w", err)
+	}
+	return volumes, nil
+}
+
+// GetFirstSentTime returns the block time of the first bridge or transfer, 0
+// when there is none
+func GetFirstSentTime(ctx context.Context) (int64, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	SELECT COALESCE(LEAST(
+		(SELECT MIN(timestamp) FROM vault_transactions WHERE timestamp > 0),
+		(SELECT MIN(block_time) FROM token_sents WHERE block_time > 0 AND source_chain <> $1)
+	), 0) as block_time
+	`
+
+	var blockTime int64
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, config.Env.BITCOIN_CHAIN_ID).Scan(&blockTime).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch first sent time: %w", err)
+	}
+	return blockTime, nil
+}
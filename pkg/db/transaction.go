@@ -3,14 +3,14 @@ package db
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/scalarorg/scalar-service/pkg/chainid"
 	"github.com/scalarorg/scalar-service/pkg/types"
 )
 
-func StatTransactionBySourceChain(limit int) ([]*types.ChainAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func StatTransactionBySourceChain(ctx context.Context, q LeaderboardQuery) ([]*types.ChainAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	var stats []*types.ChainAmount
@@ -18,34 +18,33 @@ func StatTransactionBySourceChain(limit int) ([]*types.ChainAmount, error) {
 	// Optimized query with proper indexing
 	query := `
 		SELECT 
-			chain,
+			` + chainid.SQL("chain") + ` as chain,
 			COUNT(*) as amount
 		FROM vault_transactions
 		WHERE chain IS NOT NULL
 			AND chain != ''
 			AND TRIM(chain) != ''
-		GROUP BY chain
+			AND ($2::bigint = 0 OR timestamp >= $2::bigint)
+			AND ($3::bigint = 0 OR timestamp < $3::bigint)
+		GROUP BY 1
 		ORDER BY amount DESC
-		LIMIT $1
+		LIMIT $1 OFFSET $4
 	`
 
-	err := DB.Indexer.WithContext(ctx).Raw(query, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, q.Limit, q.From, q.To, q.Offset).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transaction stats by source chain: %w", err)
 	}
 
-	// Optimize chain name formatting for consistency
 	for i := range stats {
-		// Handle different chain formats (bitcoin|4, evm|ethereum, etc.)
-		if !strings.Contains(stats[i].Chain, "|") {
-			stats[i].Chain = "evm|" + stats[i].Chain
-		}
+		stats[i].Rank = q.Offset + i + 1
+		stats[i].Chain = chainid.Normalize(stats[i].Chain)
 	}
 	return stats, nil
 }
 
-func StatTransactionByDestinationChain(limit int) ([]*types.ChainAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func StatTransactionByDestinationChain(ctx context.Context, q LeaderboardQuery) ([]*types.ChainAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	var stats []*types.ChainAmount
@@ -53,31 +52,30 @@ func StatTransactionByDestinationChain(limit int) ([]*types.ChainAmount, error)
 	// Optimized query with proper filtering
 	query := `
 		SELECT 
-			destination_chain as chain,
+			` + chainid.SQL("destination_chain") + ` as chain,
 			COUNT(*) as amount
 		FROM vault_transactions
-		GROUP BY destination_chain
+		WHERE ($2::bigint = 0 OR timestamp >= $2::bigint)
+			AND ($3::bigint = 0 OR timestamp < $3::bigint)
+		GROUP BY 1
 		ORDER BY amount DESC
-		LIMIT $1
+		LIMIT $1 OFFSET $4
 	`
 
-	err := DB.Indexer.WithContext(ctx).Raw(query, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, q.Limit, q.From, q.To, q.Offset).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transaction stats by destination chain: %w", err)
 	}
 
-	// Optimize chain name formatting for consistency
 	for i := range stats {
-		// Handle different chain formats (bitcoin|4, evm|ethereum, etc.)
-		if !strings.Contains(stats[i].Chain, "|") {
-			stats[i].Chain = "evm|" + stats[i].Chain
-		}
+		stats[i].Rank = q.Offset + i + 1
+		stats[i].Chain = chainid.Normalize(stats[i].Chain)
 	}
 	return stats, nil
 }
 
-func StatTransactionByPath(limit int) ([]*types.PathAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func StatTransactionByPath(ctx context.Context, q LeaderboardQuery) ([]*types.PathAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
 	var stats []*types.PathAmount
@@ -86,30 +84,26 @@ func StatTransactionByPath(limit int) ([]*types.PathAmount, error) {
 	// Use direct column names without COALESCE to avoid type conversion issues
 	query := `
 		SELECT 
-		chain as source_chain,
-		destination_chain,
+		` + chainid.SQL("chain") + ` as source_chain,
+		` + chainid.SQL("destination_chain") + ` as destination_chain,
 		COUNT(*) as amount
 		FROM vault_transactions
-		GROUP BY source_chain, destination_chain
+		WHERE ($2::bigint = 0 OR timestamp >= $2::bigint)
+			AND ($3::bigint = 0 OR timestamp < $3::bigint)
+		GROUP BY 1, 2
 		ORDER BY amount DESC
-		LIMIT $1
+		LIMIT $1 OFFSET $4
 	`
 
-	err := DB.Indexer.WithContext(ctx).Raw(query, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, q.Limit, q.From, q.To, q.Offset).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch transaction stats by path: %w", err)
 	}
 
-	// Optimize chain name formatting for consistency
 	for i := range stats {
-		// Handle different chain formats for source chain
-		if !strings.Contains(stats[i].SourceChain, "|") {
-			stats[i].SourceChain = "evm|" + stats[i].SourceChain
-		}
-		// Handle different chain formats for destination chain
-		if !strings.Contains(stats[i].DestinationChain, "|") {
-			stats[i].DestinationChain = "evm|" + stats[i].DestinationChain
-		}
+		stats[i].Rank = q.Offset + i + 1
+		stats[i].SourceChain = chainid.Normalize(stats[i].SourceChain)
+		stats[i].DestinationChain = chainid.Normalize(stats[i].DestinationChain)
 	}
 	return stats, nil
 }
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+)
+
+// VaultFlow is the BTC locked into and released from the vault of a bitcoin
+// network in one bucket, for the tokens held on one destination chain
+type VaultFlow struct {
+	BucketTime time.Time `gorm:"column:bucket_time"`
+	Network    string    `gorm:"column:network"`
+	Chain      string    `gorm:"column:chain"`
+	Inflow     uint64    `gorm:"column:inflow"`
+	Outflow    uint64    `gorm:"column:outflow"`
+}
+
+// GetVaultFlows returns the vault flows of every bucket since the first bridge,
+// ordered by time. Bridges lock BTC when their vault transaction is seen and
+// redeems release it once their bitcoin transaction is. Buckets are cut in the
+// tz time zone, an empty network or chain matches every one.
+func GetVaultFlows(ctx context.Context, timeBucket, tz, network, chain string) ([]VaultFlow, error) {
+	if !validateTimeBucketInterval(timeBucket) {
+		return nil, fmt.Errorf("invalid bucket name")
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH flows AS (
+		SELECT
+			` + chainid.SQL("chain") + ` as network,
+			` + chainid.SQL("destination_chain") + ` as chain,
+			timestamp as block_time,
+			amount as inflow,
+			0 as outflow
+		FROM vault_transactions
+		WHERE amount > 0
+			AND timestamp IS NOT NULL
+			AND (cardinality($3::text[]) = 0 OR chain = ANY($3::text[]))
+			AND (cardinality($4::text[]) = 0 OR destination_chain = ANY($4::text[]))
+		UNION ALL
+		SELECT
+			` + chainid.SQL("ert.destination_chain") + ` as network,
+			` + chainid.SQL("ert.source_chain") + ` as chain,
+			brt.block_time,
+			0 as inflow,
+			ert.amount as outflow
+		FROM evm_redeem_txes ert
+		INNER JOIN btc_redeem_txes brt ON ert.custodian_group_uid = brt.custodian_group_uid AND ert.session_sequence = brt.session_sequence
+		WHERE ert.amount > 0
+			AND brt.block_time IS NOT NULL
+			AND (cardinality($3::text[]) = 0 OR ert.destination_chain = ANY($3::text[]))
+			AND (cardinality($4::text[]) = 0 OR ert.source_chain = ANY($4::text[]))
+	)
+	SELECT
+		date_trunc($1, to_timestamp(block_time) AT TIME ZONE $2) AT TIME ZONE $2 as bucket_time,
+		network,
+		chain,
+		SUM(inflow) as inflow,
+		SUM(outflow) as outflow
+	FROM flows
+	GROUP BY bucket_time, network, chain
+	ORDER BY bucket_time ASC
+	`
+
+	var flows []VaultFlow
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, timeBucket, tz, textArray(chainid.Forms(network)), textArray(chainid.Forms(chain))).Scan(&flows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vault flows: %w", err)
+	}
+	return flows, nil
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+	"github.com/scalarorg/scalar-service/pkg/identity"
+)
+
+type UserActivity struct {
+	Kind             CrossChainTx `gorm:"column:kind"`
+	SourceChain      string       `gorm:"column:source_chain"`
+	DestinationChain string       `gorm:"column:destination_chain"`
+	Asset            string       `gorm:"column:asset"`
+	Amount           uint64       `gorm:"column:amount"`
+	Count            uint64       `gorm:"column:count"`
+	FirstSeen        int64        `gorm:"column:first_seen"`
+	LastSeen         int64        `gorm:"column:last_seen"`
+}
+
+// GetUserActivity aggregates the bridges, transfers and redeems of one user per
+// kind, path and asset. script is the staker script pubkey of a bitcoin address
+// and evmAddress a lowercased EVM address, either may be empty.
+func GetUserActivity(ctx context.Context, script string, evmAddress string) ([]UserActivity, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH events AS (
+		SELECT
+			'bridge' as kind,
+			chain as source_chain,
+			destination_chain,
+			'BTC' as asset,
+			amount,
+			timestamp as block_time
+		FROM vault_transactions
+		WHERE amount > 0
+			AND timestamp IS NOT NULL
+			AND (($1::text != '' AND staker_script_pubkey = $1::text)
+				OR ($2::text != '' AND LOWER(destination_recipient_address) = $2::text))
+		UNION ALL
+		SELECT
+			'transfer' as kind,
+			source_chain,
+			destination_chain,
+			COALESCE(symbol, '') as asset,
+			amount,
+			block_time
+		FROM token_sents
+		WHERE $2::text != ''
+			AND LOWER(source_address) = $2::text
+			AND source_chain <> $3::text
+			AND amount > 0
+		UNION ALL
+		SELECT
+			'redeem' as kind,
+			ert.source_chain,
+			ert.destination_chain,
+			COALESCE(ert.symbol, '') as asset,
+			ert.amount,
+			dbh.block_time
+		FROM evm_redeem_txes ert
+		LEFT JOIN block_headers dbh ON ert.source_chain = dbh.chain AND ert.block_number = dbh.block_number
+		WHERE $2::text != ''
+			AND LOWER(ert.source_address) = $2::text
+			AND ert.amount > 0
+	)
+	SELECT
+		kind,
+		` + chainid.SQL("source_chain") + ` as source_chain,
+		` + chainid.SQL("destination_chain") + ` as destination_chain,
+		asset,
+		SUM(amount) as amount,
+		COUNT(*) as count,
+		COALESCE(MIN(block_time), 0) as first_seen,
+		COALESCE(MAX(block_time), 0) as last_seen
+	FROM events
+	GROUP BY 1, 2, 3, 4
+	`
+
+	var activity []UserActivity
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, script, evmAddress, config.Env.BITCOIN_CHAIN_ID).Scan(&activity).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user activity: %w", err)
+	}
+	return activity, nil
+}
+
+// GetBridgeRank returns the position of a staker on the top bridges leaderboard
+// of chain, or 0 if they never bridged
+func GetBridgeRank(ctx context.Context, chain string, script string) (int64, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH user_volume AS (
+		SELECT COALESCE(SUM(amount), 0) as amount
+		FROM vault_transactions
+		WHERE chain = $1
+			AND staker_script_pubkey = $2
+			AND amount > 0
+	)
+	SELECT
+		CASE WHEN (SELECT amount FROM user_volume) = 0 THEN 0
+		ELSE (
+			SELECT COUNT(*) + 1
+			FROM (
+				SELECT SUM(amount) as amount
+				FROM vault_transactions
+				WHERE chain = $1
+					AND staker_script_pubkey IS NOT NULL
+					AND staker_script_pubkey != ''
+					AND amount > 0
+				GROUP BY staker_script_pubkey
+			) volumes
+			WHERE volumes.amount > (SELECT amount FROM user_volume)
+		) END as rank
+	`
+
+	var rank int64
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, chain, script).Scan(&rank).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch bridge rank: %w", err)
+	}
+	return rank, nil
+}
+
+// UserIdentityRank is the identity of an address with its position on the top
+// users leaderboard, 0 when it has no volume of the ranked asset
+type UserIdentityRank struct {
+	Script  string `gorm:"column:script"`
+	Network string `gorm:"column:network"`
+	Address string `gorm:"column:address"`
+	// Comma separated EVM recipients of the staker
+	Linked string `gorm:"column:linked"`
+	Rank   int64  `gorm:"column:rank"`
+}
+
+// GetUserIdentityRank returns the identity of a staker script or of a
+// lowercased EVM address, and its all time rank on the top users leaderboard
+func GetUserIdentityRank(ctx context.Context, script string, evmAddress string) (*UserIdentityRank, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+
+	rawQuery := `
+	WITH ` + identityVolumesSQL + `,
+	me AS (
+		SELECT $4::text as script, $3::text as network, ''::text as address
+		WHERE $4::text != ''
+		UNION ALL
+		SELECT
+			COALESCE(o.script, '') as script,
+			COALESCE(o.chain, '') as network,
+			CASE WHEN o.script IS NULL THEN e.recipient ELSE '' END as address
+		FROM (SELECT $5::text as recipient) e
+		LEFT JOIN owners o ON o.recipient = e.recipient
+		WHERE $5::text != ''
+	),
+	totals AS (
+		SELECT script, network, address, SUM(amount) as total
+		FROM volumes
+		WHERE asset = $6
+		GROUP BY 1, 2, 3
+	)
+	SELECT
+		me.script,
+		me.network,
+		me.address,
+		COALESCE(l.linked, '') as linked,
+		CASE WHEN COALESCE(t.total, 0) = 0 THEN 0
+		ELSE (SELECT COUNT(*) + 1 FROM totals other WHERE other.total > t.total) END as rank
+	FROM me
+	LEFT JOIN totals t ON t.script = me.script AND t.network = me.network AND t.address = me.address
+	LEFT JOIN links l ON l.script = me.script AND l.network = me.network
+	LIMIT 1
+	`
+
+	var rank UserIdentityRank
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(rawQuery, 0, 0, config.Env.BITCOIN_CHAIN_ID, script, evmAddress, identity.DefaultAsset).Scan(&rank).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user rank: %w", err)
+	}
+	return &rank, nil
+}
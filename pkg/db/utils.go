@@ -1,5 +1,7 @@
 package db
 
+import "strings"
+
 func CreateCrossChainDocument(sent ExpectedCrossChainDocument) *CrossChainDocument {
 	return &CrossChainDocument{
 		ID:          sent.GetID(),
@@ -11,6 +13,17 @@ func CreateCrossChainDocument(sent ExpectedCrossChainDocument) *CrossChainDocume
 	}
 }
 
+// LeaderboardQuery selects one page of a leaderboard over a time range
+type LeaderboardQuery struct {
+	Limit  int
+	Offset int
+	// Unix time range, a zero bound is open
+	From int64
+	To   int64
+	// Asset ranking the identity leaderboards
+	Asset string
+}
+
 func getTimeBucketInterval(bucket string) string {
 	switch bucket {
 	case "hour":
@@ -45,32 +58,14 @@ func validateTimeBucketInterval(bucket string) bool {
 	return false
 }
 
-// mergeSortedEntries merges two sorted slices of Entry
-func mergeSortedStats[T any](a, b []T, compare func(a, b T) int, merge func(a, b T) T) []T {
-	var result []T
-	i, j := 0, 0
-
-	for i < len(a) && j < len(b) {
-		if compare(a[i], b[j]) == 0 {
-			result = append(result, merge(a[i], b[j]))
-			i++
-			j++
-		} else if compare(a[i], b[j]) < 0 {
-			result = append(result, a[i])
-			i++
-		} else {
-			result = append(result, b[j])
-			j++
-		}
+// textArray formats values as a postgres text array literal, to be cast with
+// ::text[] in raw queries
+func textArray(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+		v = strings.ReplaceAll(v, `"`, `\"`)
+		quoted[i] = `"` + v + `"`
 	}
-
-	// Append any remaining entries
-	for ; i < len(a); i++ {
-		result = append(result, a[i])
-	}
-	for ; j < len(b); j++ {
-		result = append(result, b[j])
-	}
-
-	return result
+	return "{" + strings.Join(quoted, ",") + "}"
 }
@@ -3,100 +3,220 @@ package db
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/chainid"
+	"github.com/scalarorg/scalar-service/pkg/identity"
 	"github.com/scalarorg/scalar-service/pkg/types"
 	"github.com/scalarorg/scalar-service/pkg/utils"
 )
 
-// Get top users by volume with optimized parallel queries and context timeout
-func GetTopTransferUsers(limit int) ([]types.AddressAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// identityVolume is the volume of one identity on one chain in one asset. An
+// identity is a staker, keyed by script and network, or an EVM address that no
+// staker bridged to.
+type identityVolume struct {
+	Script  string `gorm:"column:script"`
+	Network string `gorm:"column:network"`
+	Address string `gorm:"column:address"`
+	// Comma separated EVM recipients of the staker
+	Linked string `gorm:"column:linked"`
+	Total  uint64 `gorm:"column:total"`
+	Chain  string `gorm:"column:chain"`
+	Asset  string `gorm:"column:asset"`
+	Amount uint64 `gorm:"column:amount"`
+	Count  uint64 `gorm:"column:count"`
+}
+
+// Get top users by volume, aggregated per identity: a BTC staker is merged with
+// the EVM recipients they bridged to so each real user appears once
+func GetTopTransferUsers(ctx context.Context, q LeaderboardQuery) ([]types.AddressAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
-	
-	wg := sync.WaitGroup{}
-	wg.Add(2)
-	var transferStats []types.AddressAmount
-	var transferErr error
-	
-	go func() {
-		defer wg.Done()
-		// Optimized query with better indexing strategy and LIMIT push-down
-		query := `
-		SELECT 
-			source_address as address,
-			SUM(amount) as amount
-		FROM token_sents
-		WHERE source_chain LIKE 'evm|%'
-			AND amount > 0
-		GROUP BY source_address
-		ORDER BY amount DESC
-		LIMIT $1
-		`
-		transferErr = DB.Indexer.WithContext(ctx).Raw(query, limit*2).Scan(&transferStats).Error
-		if transferErr != nil {
-			log.Error().Err(transferErr).Msg("failed to fetch top transfer users")
-		}
-	}()
-	
-	var bridgeStats []types.AddressAmount
-	var bridgeErr error
-	
-	go func() {
-		defer wg.Done()
-		// Optimized query with better performance and proper filtering
-		query := `
-		SELECT 
-			staker_script_pubkey as address,
-			SUM(amount) as amount
+
+	identities, err := GetUserIdentities(ctxWithTimeout, q)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]types.AddressAmount, 0, len(identities))
+	for i, id := range identities {
+		stats = append(stats, types.AddressAmount{
+			Rank:      q.Offset + i + 1,
+			Address:   id.Address,
+			Amount:    id.Total,
+			Addresses: id.Addresses,
+			Chains:    chainBreakdown(id.Volumes),
+			Assets:    id.Assets,
+		})
+	}
+	return stats, nil
+}
+
+// identityVolumesSQL are the CTEs of the volume of every identity per chain
+// and asset between the unix times $1 and $2, $3 being the bitcoin chain. An
+// EVM address belongs to the staker that bridged the most to it.
+const identityVolumesSQL = `
+	stakers AS (
+		SELECT
+			staker_script_pubkey as script,
+			chain,
+			LOWER(COALESCE(destination_recipient_address, '')) as recipient,
+			SUM(amount) as amount,
+			COUNT(*) as count
 		FROM vault_transactions
 		WHERE staker_script_pubkey IS NOT NULL
 			AND staker_script_pubkey != ''
 			AND amount > 0
-		GROUP BY staker_script_pubkey
-		ORDER BY amount DESC
-		LIMIT $1
-		`
-		bridgeErr = DB.Indexer.WithContext(ctx).Raw(query, limit*2).Scan(&bridgeStats).Error
-		if bridgeErr != nil {
-			log.Error().Err(bridgeErr).Msg("failed to fetch top bridge users")
-		}
-	}()
+			AND ($1::bigint = 0 OR timestamp >= $1::bigint)
+			AND ($2::bigint = 0 OR timestamp < $2::bigint)
+		GROUP BY 1, 2, 3
+	),
+	owners AS (
+		SELECT DISTINCT ON (recipient) recipient, chain, script
+		FROM stakers
+		WHERE recipient != ''
+		ORDER BY recipient, amount DESC, script
+	),
+	links AS (
+		SELECT script, chain as network, string_agg(recipient, ',' ORDER BY recipient) as linked
+		FROM owners
+		GROUP BY 1, 2
+	),
+	transfers AS (
+		SELECT
+			LOWER(source_address) as address,
+			source_chain as chain,
+			COALESCE(symbol, '') as asset,
+			SUM(amount) as amount,
+			COUNT(*) as count
+		FROM token_sents
+		WHERE source_address IS NOT NULL
+			AND source_chain <> $3
+			AND amount > 0
+			AND ($1::bigint = 0 OR block_time >= $1::bigint)
+			AND ($2::bigint = 0 OR block_time < $2::bigint)
+		GROUP BY 1, 2, 3
+	),
+	volumes AS (
+		SELECT script, chain as network, '' as address, chain, 'BTC' as asset, amount, count
+		FROM stakers
+		UNION ALL
+		SELECT
+			COALESCE(o.script, '') as script,
+			COALESCE(o.chain, '') as network,
+			CASE WHEN o.script IS NULL THEN t.address ELSE '' END as address,
+			t.chain, t.asset, t.amount, t.count
+		FROM transfers t
+		LEFT JOIN owners o ON o.recipient = t.address
+	)`
 
-	wg.Wait()
-	
-	// Handle errors
-	if transferErr != nil && bridgeErr != nil {
-		return nil, fmt.Errorf("both queries failed: transfer=%v, bridge=%v", transferErr, bridgeErr)
+// GetUserIdentities returns a page of the user identities ranked by their
+// bridged and transferred volume of q.Asset, BTC by default, between q.From
+// and q.To. The bridged BTC and the transferred tokens are summed per asset.
+func GetUserIdentities(ctx context.Context, q LeaderboardQuery) ([]*identity.Identity, error) {
+	query := `
+	WITH ` + identityVolumesSQL + `,
+	ranked AS (
+		SELECT script, network, address, SUM(amount) as total
+		FROM volumes
+		WHERE asset = $4
+		GROUP BY 1, 2, 3
+		ORDER BY total DESC, script, network, address
+		LIMIT $5 OFFSET $6
+	)
+	SELECT
+		r.script,
+		r.network,
+		r.address,
+		r.total,
+		COALESCE(l.linked, '') as linked,
+		` + chainid.SQL("v.chain") + ` as chain,
+		v.asset,
+		SUM(v.amount) as amount,
+		SUM(v.count) as count
+	FROM ranked r
+	JOIN volumes v ON v.script = r.script AND v.network = r.network AND v.address = r.address
+	LEFT JOIN links l ON l.script = r.script AND l.network = r.network
+	GROUP BY 1, 2, 3, 4, 5, 6, 7
+	ORDER BY r.total DESC, r.script, r.network, r.address
+	`
+
+	asset := q.Asset
+	if asset == "" {
+		asset = identity.DefaultAsset
 	}
-	
-	// Merge and sort results efficiently
-	allStats := mergeSortedStats(transferStats, bridgeStats, func(a, b types.AddressAmount) int {
-		if a.Amount > b.Amount {
-			return 1
-		} else if a.Amount < b.Amount {
-			return -1
+
+	var rows []identityVolume
+	err := DB.Indexer.WithContext(ctx).Raw(query, q.From, q.To, config.Env.BITCOIN_CHAIN_ID, asset, q.Limit, q.Offset).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user identities: %w", err)
+	}
+
+	var identities []*identity.Identity
+	assets := make(map[*identity.Identity]map[string]*types.AssetAmount)
+	for _, row := range rows {
+		var id *identity.Identity
+		if n := len(identities); n > 0 && identities[n-1].Key == [3]string{row.Script, row.Network, row.Address} {
+			id = identities[n-1]
+		} else {
+			id = identity.New(row.Script, row.Network, row.Address, splitNonEmpty(row.Linked))
+			id.Total = row.Total
+			identities = append(identities, id)
+			assets[id] = make(map[string]*types.AssetAmount)
+		}
+
+		if row.Asset == asset {
+			id.Volumes[row.Chain] += row.Amount
 		}
-		return 0
-	}, func(a, b types.AddressAmount) types.AddressAmount {
-		return types.AddressAmount{
-			Address: a.Address,
-			Amount:  a.Amount + b.Amount,
+		a, ok := assets[id][row.Asset]
+		if !ok {
+			a = &types.AssetAmount{Asset: row.Asset}
+			assets[id][row.Asset] = a
 		}
-	})
+		a.Amount += row.Amount
+		a.Count += row.Count
+	}
+	for _, id := range identities {
+		for _, a := range assets[id] {
+			id.Assets = append(id.Assets, *a)
+		}
+		sort.Slice(id.Assets, func(i, j int) bool {
+			if id.Assets[i].Amount != id.Assets[j].Amount {
+				return id.Assets[i].Amount > id.Assets[j].Amount
+			}
+			return id.Assets[i].Asset < id.Assets[j].Asset
+		})
+	}
+	return identities, nil
+}
 
-	// Return top results
-	if len(allStats) > limit {
-		return allStats[len(allStats)-limit:], nil
+func splitNonEmpty(s string) []string {
+	if s == "" {
+		return nil
 	}
-	return allStats, nil
+	return strings.Split(s, ",")
 }
 
-func GetTopBridgeUsers(sourceChain string, limit int) ([]*types.AddressAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+func chainBreakdown(volumes map[string]uint64) []types.ChainAmount {
+	chains := make([]types.ChainAmount, 0, len(volumes))
+	for chain, amount := range volumes {
+		chains = append(chains, types.ChainAmount{Chain: chain, Amount: amount})
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		if chains[i].Amount != chains[j].Amount {
+			return chains[i].Amount > chains[j].Amount
+		}
+		return chains[i].Chain < chains[j].Chain
+	})
+	return chains
+}
+
+func GetTopBridgeUsers(ctx context.Context, sourceChain string, q LeaderboardQuery) ([]*types.AddressAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 	
 	var stats []*types.AddressAmount
@@ -107,16 +227,18 @@ func GetTopBridgeUsers(sourceChain string, limit int) ([]*types.AddressAmount, e
 			staker_script_pubkey as address,
 			SUM(amount) as amount
 		FROM vault_transactions
-		WHERE chain = $1
+		WHERE chain = ANY($1::text[])
 			AND staker_script_pubkey IS NOT NULL
 			AND staker_script_pubkey != ''
 			AND amount > 0
+			AND ($3::bigint = 0 OR timestamp >= $3::bigint)
+			AND ($4::bigint = 0 OR timestamp < $4::bigint)
 		GROUP BY staker_script_pubkey
 		ORDER BY amount DESC
-		LIMIT $2
+		LIMIT $2 OFFSET $5
 	`
 	
-	err := DB.Indexer.WithContext(ctx).Raw(query, sourceChain, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, textArray(chainid.Forms(sourceChain)), q.Limit, q.From, q.To, q.Offset).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch top bridge users: %w", err)
 	}
@@ -150,12 +272,16 @@ func GetTopBridgeUsers(sourceChain string, limit int) ([]*types.AddressAmount, e
 			return nil, err
 		}
 	}
+
+	for i := range stats {
+		stats[i].Rank = q.Offset + i + 1
+	}
 	
 	return stats, nil
 }
 
-func StatVolumeBySourceChain(limit int) ([]*types.ChainAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func StatVolumeBySourceChain(ctx context.Context, q LeaderboardQuery) ([]*types.ChainAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	
 	var stats []*types.ChainAmount
@@ -163,24 +289,30 @@ func StatVolumeBySourceChain(limit int) ([]*types.ChainAmount, error) {
 	// Optimized query with proper filtering and indexing
 	query := `
 		SELECT 
-			chain,
+			` + chainid.SQL("chain") + ` as chain,
 			SUM(amount) as amount
 		FROM vault_transactions
 		WHERE amount > 0
-		GROUP BY chain
+			AND ($2::bigint = 0 OR timestamp >= $2::bigint)
+			AND ($3::bigint = 0 OR timestamp < $3::bigint)
+		GROUP BY 1
 		ORDER BY amount DESC
-		LIMIT $1
+		LIMIT $1 OFFSET $4
 	`
 	
-	err := DB.Indexer.WithContext(ctx).Raw(query, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, q.Limit, q.From, q.To, q.Offset).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch volume by source chain: %w", err)
 	}
+	for i := range stats {
+		stats[i].Rank = q.Offset + i + 1
+		stats[i].Chain = chainid.Normalize(stats[i].Chain)
+	}
 	return stats, nil
 }
 
-func StatVolumeByDestinationChain(limit int) ([]*types.ChainAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func StatVolumeByDestinationChain(ctx context.Context, q LeaderboardQuery) ([]*types.ChainAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	
 	var stats []*types.ChainAmount
@@ -188,31 +320,31 @@ func StatVolumeByDestinationChain(limit int) ([]*types.ChainAmount, error) {
 	// Optimized query with proper filtering
 	query := `
 		SELECT 
-			destination_chain as chain,
+			` + chainid.SQL("destination_chain") + ` as chain,
 			SUM(amount) as amount
 		FROM vault_transactions
 		WHERE amount > 0
-		GROUP BY destination_chain
+			AND ($2::bigint = 0 OR timestamp >= $2::bigint)
+			AND ($3::bigint = 0 OR timestamp < $3::bigint)
+		GROUP BY 1
 		ORDER BY amount DESC
-		LIMIT $1
+		LIMIT $1 OFFSET $4
 	`
 	
-	err := DB.Indexer.WithContext(ctx).Raw(query, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, q.Limit, q.From, q.To, q.Offset).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch volume by destination chain: %w", err)
 	}
 	
-	// Optimize chain name formatting
 	for i := range stats {
-		if !strings.HasPrefix(stats[i].Chain, "evm|") {
-			stats[i].Chain = "evm|" + stats[i].Chain
-		}
+		stats[i].Rank = q.Offset + i + 1
+		stats[i].Chain = chainid.Normalize(stats[i].Chain)
 	}
 	return stats, nil
 }
 
-func StatVolumeByPath(limit int) ([]*types.PathAmount, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func StatVolumeByPath(ctx context.Context, q LeaderboardQuery) ([]*types.PathAmount, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 	
 	var stats []*types.PathAmount
@@ -220,26 +352,27 @@ func StatVolumeByPath(limit int) ([]*types.PathAmount, error) {
 	// Optimized query with proper filtering and composite index usage
 	query := `
 		SELECT 
-			chain as source_chain,
-			destination_chain,
+			` + chainid.SQL("chain") + ` as source_chain,
+			` + chainid.SQL("destination_chain") + ` as destination_chain,
 			SUM(amount) as amount
 		FROM vault_transactions
 		WHERE amount > 0
-		GROUP BY chain, destination_chain
+			AND ($2::bigint = 0 OR timestamp >= $2::bigint)
+			AND ($3::bigint = 0 OR timestamp < $3::bigint)
+		GROUP BY 1, 2
 		ORDER BY amount DESC
-		LIMIT $1
+		LIMIT $1 OFFSET $4
 	`
 	
-	err := DB.Indexer.WithContext(ctx).Raw(query, limit).Scan(&stats).Error
+	err := DB.Indexer.WithContext(ctxWithTimeout).Raw(query, q.Limit, q.From, q.To, q.Offset).Scan(&stats).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch volume by path: %w", err)
 	}
 	
-	// Optimize chain name formatting
 	for i := range stats {
-		if !strings.HasPrefix(stats[i].DestinationChain, "evm|") {
-			stats[i].DestinationChain = "evm|" + stats[i].DestinationChain
-		}
+		stats[i].Rank = q.Offset + i + 1
+		stats[i].SourceChain = chainid.Normalize(stats[i].SourceChain)
+		stats[i].DestinationChain = chainid.Normalize(stats[i].DestinationChain)
 	}
 	return stats, nil
 }
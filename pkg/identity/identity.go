@@ -0,0 +1,91 @@
+package identity
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/pkg/types"
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// DefaultAsset ranks the identities unless another asset is asked for, it is
+// the asset of the vault bridges
+const DefaultAsset = "BTC"
+
+// Identity is a real user behind one or more addresses: a BTC staker together
+// with the EVM recipients they bridged to and sent tokens from.
+type Identity struct {
+	// Key is the staker script, its network and the EVM address of a user who
+	// never bridged from bitcoin, which is empty for stakers
+	Key       [3]string
+	Address   string
+	Addresses []string
+	// Volume of the ranked asset per chain and in total
+	Volumes map[string]uint64
+	Total   uint64
+	// Volume of every asset
+	Assets []types.AssetAmount
+}
+
+// New returns the identity of a staker and its linked EVM addresses, or of a
+// lone EVM address when script is empty
+func New(script, network, address string, linked []string) *Identity {
+	id := &Identity{
+		Key:     [3]string{script, network, address},
+		Volumes: make(map[string]uint64),
+	}
+	if script != "" {
+		id.Address = ScriptToAddress(script, network)
+	} else {
+		id.Address = Normalize(address)
+	}
+
+	id.Addresses = append(id.Addresses, id.Address)
+	for _, address := range linked {
+		if address = Normalize(address); address != "" && !slices.Contains(id.Addresses, address) {
+			id.Addresses = append(id.Addresses, address)
+		}
+	}
+	sortAddresses(id.Addresses)
+	return id
+}
+
+// Normalize returns the canonical spelling of an address. EVM addresses are
+// case-insensitive so they are lowercased, everything else is kept as is.
+func Normalize(address string) string {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+func IsEVMAddress(address string) bool {
+	return strings.HasPrefix(Normalize(address), "0x")
+}
+
+// ScriptToAddress converts a staker script pubkey to its address on network.
+// The raw script is returned when it can't be decoded so the user isn't lost.
+func ScriptToAddress(script string, network string) string {
+	address, err := utils.ScriptPubKeyToAddress(script, network)
+	if err != nil {
+		log.Debug().Err(err).Str("script", script).Str("network", network).Msg("failed to convert script pubkey to address")
+		return script
+	}
+	return address.String()
+}
+
+// sortAddresses keeps the primary address first and sorts the linked ones,
+// BTC addresses first since the staker is the primary identity
+func sortAddresses(addresses []string) {
+	sort.SliceStable(addresses[1:], func(i, j int) bool {
+		a, b := addresses[1+i], addresses[1+j]
+		ea, eb := IsEVMAddress(a), IsEVMAddress(b)
+		if ea != eb {
+			return !ea
+		}
+		return a < b
+	})
+}
@@ -0,0 +1,87 @@
+package identity
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+const (
+	testnet = "bitcoin|4"
+	// BIP 173 test vector
+	btcAddress = "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"0xAbCdEf0000000000000000000000000000000001", "0xabcdef0000000000000000000000000000000001"},
+		{"0XABCDEF0000000000000000000000000000000001", "0xabcdef0000000000000000000000000000000001"},
+		{"  0xabc ", "0xabc"},
+		{btcAddress, btcAddress},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.address); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestScriptToAddress(t *testing.T) {
+	script, err := utils.AddressToScriptPubKey(btcAddress, testnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ScriptToAddress(script, testnet); got != btcAddress {
+		t.Errorf("ScriptToAddress(%q) = %q, want %q", script, got, btcAddress)
+	}
+	// Scripts that can't be decoded are kept so the user isn't lost
+	if got := ScriptToAddress("zz", testnet); got != "zz" {
+		t.Errorf("ScriptToAddress of an invalid script = %q, want it unchanged", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	script, err := utils.AddressToScriptPubKey(btcAddress, testnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		script        string
+		address       string
+		linked        []string
+		wantAddress   string
+		wantAddresses []string
+	}{
+		{
+			name:          "staker with recipients",
+			script:        script,
+			linked:        []string{"0xBB", "0xaa", "0xbb"},
+			wantAddress:   btcAddress,
+			wantAddresses: []string{btcAddress, "0xaa", "0xbb"},
+		},
+		{
+			name:          "lone EVM address",
+			address:       "0xCC",
+			wantAddress:   "0xcc",
+			wantAddresses: []string{"0xcc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := New(tt.script, testnet, tt.address, tt.linked)
+			if id.Address != tt.wantAddress {
+				t.Errorf("Address = %q, want %q", id.Address, tt.wantAddress)
+			}
+			if !reflect.DeepEqual(id.Addresses, tt.wantAddresses) {
+				t.Errorf("Addresses = %q, want %q", id.Addresses, tt.wantAddresses)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package metrics
+
+import (
+	"context"
+	"maps"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+	"github.com/scalarorg/scalar-service/pkg/db"
+)
+
+// Collector refreshes the protocol metrics from the database on a fixed
+// interval, one collection at a time
+type Collector struct {
+	interval time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+var Default *Collector
+
+func Init() {
+	Default = NewCollector(time.Duration(config.Env.METRICS_INTERVAL) * time.Second)
+	Default.Start()
+	log.Info().Dur("interval", Default.interval).Msg("Metrics collector started")
+}
+
+func Close() {
+	if Default != nil {
+		Default.Stop()
+	}
+}
+
+func NewCollector(interval time.Duration) *Collector {
+	return &Collector{interval: interval}
+}
+
+func (c *Collector) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.collect(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// A tick missed while collecting is dropped rather than queued
+				c.collect(ctx)
+			}
+		}
+	}()
+}
+
+func (c *Collector) Stop() {
+	if c.cancel == nil {
+		return
+	}
+	c.cancel()
+	<-c.done
+}
+
+// collect runs every query in parallel, bounded by the interval so that a slow
+// database never stacks collections
+func (c *Collector) collect(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.interval)
+	defer cancel()
+
+	start := time.Now()
+	queries := map[string]func(ctx context.Context) error{
+		"bridged_volume":    volumes.collect,
+		"pending_transfers": collectPendingTransfers,
+		"redeem_backlog":    collectRedeemBacklog,
+		"latest_blocks":     collectLatestBlocks,
+		"latest_txs":        collectLatestTxs,
+	}
+
+	wg := sync.WaitGroup{}
+	for name, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := query(ctx); err != nil {
+				collectErrors.WithLabelValues(name).Inc()
+				log.Error().Err(err).Str("query", name).Msg("failed to collect metrics")
+			}
+		}()
+	}
+	wg.Wait()
+
+	collectDuration.Set(time.Since(start).Seconds())
+	collectTimestamp.SetToCurrentTime()
+}
+
+// settleDelay is the age of the transactions whose volume is settled, the
+// indexer has stored every transaction of a block by then. settleStep bounds
+// the time range summed by one query.
+const (
+	settleDelay = time.Hour
+	settleStep  = 30 * 24 * time.Hour
+)
+
+type volumeKey struct {
+	chain string
+	asset string
+}
+
+// sentVolume keeps the volume sent out of each chain incrementally: the
+// transactions older than settleDelay are summed once and each collection only
+// sums the ones since. The progress of the first sum is kept across the
+// collections it takes.
+type sentVolume struct {
+	settledUntil int64
+	settled      map[volumeKey]uint64
+}
+
+var volumes = &sentVolume{settled: make(map[volumeKey]uint64)}
+
+func (v *sentVolume) collect(ctx context.Context) error {
+	cutoff := time.Now().Add(-settleDelay).Unix()
+	if v.settledUntil == 0 {
+		first, err := db.GetFirstSentTime(ctx)
+		if err != nil {
+			return err
+		}
+		v.settledUntil = min(first, cutoff)
+		if first == 0 {
+			v.settledUntil = cutoff
+		}
+	}
+	for v.settledUntil < cutoff {
+		until := min(v.settledUntil+int64(settleStep.Seconds()), cutoff)
+		amounts, err := db.GetSentVolume(ctx, v.settledUntil, until)
+		if err != nil {
+			return err
+		}
+		for _, a := range amounts {
+			v.settled[volumeKey{a.Chain, a.Asset}] += a.Amount
+		}
+		v.settledUntil = until
+	}
+
+	recent, err := db.GetSentVolume(ctx, v.settledUntil, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+	totals := maps.Clone(v.settled)
+	for _, a := range recent {
+		totals[volumeKey{a.Chain, a.Asset}] += a.Amount
+	}
+
+	samples := make([]sample, 0, len(totals))
+	for key, amount := range totals {
+		samples = append(samples, sample{labels: []string{key.chain, key.asset}, value: float64(amount)})
+	}
+	bridgedVolume.set(samples)
+	return nil
+}
+
+// pendingSince is the start of the window the pending transfers and redeems
+// are looked for in
+func pendingSince() int64 {
+	return time.Now().Add(-time.Duration(config.Env.METRICS_PENDING_WINDOW) * time.Second).Unix()
+}
+
+func collectPendingTransfers(ctx context.Context) error {
+	paths, err := db.GetPendingTransfers(ctx, pendingSince())
+	if err != nil {
+		return err
+	}
+
+	counts := make([]sample, 0, len(paths))
+	amounts := make([]sample, 0, len(paths))
+	for _, p := range paths {
+		labels := []string{p.SourceChain, p.DestinationChain}
+		counts = append(counts, sample{labels: labels, value: float64(p.Count)})
+		amounts = append(amounts, sample{labels: labels, value: float64(p.Amount)})
+	}
+	pendingTransfers.set(counts)
+	pendingTransfersAmount.set(amounts)
+	return nil
+}
+
+func collectRedeemBacklog(ctx context.Context) error {
+	backlog, err := db.GetRedeemBacklog(ctx, pendingSince())
+	if err != nil {
+		return err
+	}
+
+	counts := make([]sample, 0, len(backlog))
+	amounts := make([]sample, 0, len(backlog))
+	for _, b := range backlog {
+		labels := []string{b.Chain}
+		counts = append(counts, sample{labels: labels, value: float64(b.Count)})
+		amounts = append(amounts, sample{labels: labels, value: float64(b.Amount)})
+	}
+	redeemBacklog.set(counts)
+	redeemBacklogAmount.set(amounts)
+	return nil
+}
+
+func collectLatestBlocks(ctx context.Context) error {
+	blocks, err := db.GetLatestBlocks(ctx)
+	if err != nil {
+		return err
+	}
+
+	latest := make(map[string]int64, len(blocks))
+	for _, b := range blocks {
+		latest[b.Chain] = b.BlockTime
+	}
+	ages.setBlocks(latest)
+	return nil
+}
+
+func collectLatestTxs(ctx context.Context) error {
+	txs, err := db.GetLatestTxs(ctx, pendingSince())
+	if err != nil {
+		return err
+	}
+
+	latest := make(map[string]int64, len(txs))
+	for _, tx := range txs {
+		if tx.BlockTime > 0 {
+			latest[string(tx.Kind)] = tx.BlockTime
+		}
+	}
+	ages.setTxs(latest)
+	return nil
+}
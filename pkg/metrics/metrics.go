@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "scalar"
+
+var (
+	registry = prometheus.NewRegistry()
+	handler  = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+)
+
+// The protocol gauges are replaced as a whole by each collection
+var (
+	bridgedVolume = newSnapshot("bridged_volume",
+		"Total volume bridged and transferred out of each chain per asset, in the smallest unit of the asset.",
+		"chain", "asset")
+
+	pendingTransfers = newSnapshot("pending_transfers",
+		"Bridges and transfers of the pending window not executed on their destination chain yet, per path.",
+		"source_chain", "destination_chain")
+
+	pendingTransfersAmount = newSnapshot("pending_transfers_amount",
+		"Amount of the pending bridges and transfers, per path.",
+		"source_chain", "destination_chain")
+
+	redeemBacklog = newSnapshot("redeem_backlog",
+		"Redeems of the pending window requested on each chain without a bitcoin redeem transaction yet.",
+		"chain")
+
+	redeemBacklogAmount = newSnapshot("redeem_backlog_amount",
+		"Amount of the redeem backlog of each chain.",
+		"chain")
+)
+
+var (
+	collectDuration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "metrics_collect_duration_seconds",
+		Help:      "Duration of the last collection of the protocol metrics.",
+	})
+
+	collectTimestamp = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "metrics_collect_timestamp_seconds",
+		Help:      "Unix time of the last collection of the protocol metrics.",
+	})
+
+	collectErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "metrics_collect_errors_total",
+		Help:      "Failed queries while collecting the protocol metrics.",
+	}, []string{"query"})
+
+	latestBlockAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "latest_block_age_seconds"),
+		"Age of the latest block indexed on each chain. The chain tip is not known, so it also grows between two blocks.",
+		[]string{"chain"}, nil,
+	)
+
+	latestTxAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "latest_tx_age_seconds"),
+		"Age of the latest transaction of each type, absent when there is none in the pending window.",
+		[]string{"type"}, nil,
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		bridgedVolume,
+		pendingTransfers,
+		pendingTransfersAmount,
+		redeemBacklog,
+		redeemBacklogAmount,
+		collectDuration,
+		collectTimestamp,
+		collectErrors,
+		ages,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the registry. Scrapes only read the values of the last
+// collection, so their cost doesn't depend on the scrape interval.
+func Handler() http.Handler {
+	return handler
+}
+
+// ageCollector turns the timestamps of the last collection into ages at
+// scrape time, so they keep growing between collections
+type ageCollector struct {
+	lock         sync.RWMutex
+	latestBlocks map[string]int64
+	latestTxs    map[string]int64
+}
+
+var ages = &ageCollector{
+	latestBlocks: make(map[string]int64),
+	latestTxs:    make(map[string]int64),
+}
+
+func (a *ageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- latestBlockAgeDesc
+	ch <- latestTxAgeDesc
+}
+
+func (a *ageCollector) Collect(ch chan<- prometheus.Metric) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	now := time.Now()
+	for chain, blockTime := range a.latestBlocks {
+		ch <- prometheus.MustNewConstMetric(latestBlockAgeDesc, prometheus.GaugeValue, now.Sub(time.Unix(blockTime, 0)).Seconds(), chain)
+	}
+	for typ, blockTime := range a.latestTxs {
+		ch <- prometheus.MustNewConstMetric(latestTxAgeDesc, prometheus.GaugeValue, now.Sub(time.Unix(blockTime, 0)).Seconds(), typ)
+	}
+}
+
+func (a *ageCollector) setBlocks(blocks map[string]int64) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.latestBlocks = blocks
+}
+
+func (a *ageCollector) setTxs(txs map[string]int64) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.latestTxs = txs
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+// snapshot is a gauge whose samples are all replaced at once, so that a scrape
+// never sees a collection half set
+type snapshot struct {
+	desc    *prometheus.Desc
+	lock    sync.RWMutex
+	samples []sample
+}
+
+func newSnapshot(name, help string, labels ...string) *snapshot {
+	return &snapshot{desc: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, nil)}
+}
+
+func (s *snapshot) Describe(ch chan<- *prometheus.Desc) {
+	ch <- s.desc
+}
+
+func (s *snapshot) Collect(ch chan<- prometheus.Metric) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for _, sample := range s.samples {
+		ch <- prometheus.MustNewConstMetric(s.desc, prometheus.GaugeValue, sample.value, sample.labels...)
+	}
+}
+
+func (s *snapshot) set(samples []sample) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.samples = samples
+}
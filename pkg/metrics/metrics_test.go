@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestSnapshotReplacesSamples(t *testing.T) {
+	s := newSnapshot("test_volume", "Test volume.", "chain", "asset")
+	s.set([]sample{
+		{labels: []string{"bitcoin|4", "BTC"}, value: 1},
+		{labels: []string{"evm|1", "USDC"}, value: 2},
+	})
+	s.set([]sample{{labels: []string{"evm|1", "USDC"}, value: 3}})
+
+	ch := make(chan prometheus.Metric, 10)
+	s.Collect(ch)
+	close(ch)
+
+	var metrics []*dto.Metric
+	for m := range ch {
+		var out dto.Metric
+		if err := m.Write(&out); err != nil {
+			t.Fatal(err)
+		}
+		metrics = append(metrics, &out)
+	}
+	if len(metrics) != 1 {
+		t.Fatalf("collected %d samples, want 1", len(metrics))
+	}
+	if got := metrics[0].GetGauge().GetValue(); got != 3 {
+		t.Errorf("value = %v, want 3", got)
+	}
+	labels := make(map[string]string)
+	for _, l := range metrics[0].GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	if labels["chain"] != "evm|1" || labels["asset"] != "USDC" {
+		t.Errorf("labels = %v, want chain evm|1 and asset USDC", labels)
+	}
+}
@@ -0,0 +1,269 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+const Version = "3.1.0"
+
+// Security schemes of the operations
+const (
+	SecurityAPIKey = "apiKey"
+	SecurityAdmin  = "adminToken"
+)
+
+// Operation documents the route of Handler. Params is a struct whose param
+// and query tags are the path and query parameters, Body the JSON request
+// body and Response the JSON response body, each a zero value of its type or
+// a OneOf of them.
+type Operation struct {
+	Handler     any
+	Summary     string
+	Description string
+	Tag         string
+	Params      any
+	Body        any
+	Response    any
+	// Data of the Envelope on the versioned API when it is not Response, as
+	// for the lists of the legacy API
+	Data any
+	// ContentType of a response which is not JSON
+	ContentType string
+	// Status of the response, 200 by default
+	Status int
+	// Headers of the response with their description
+	Headers map[string]string
+	// Security is SecurityAdmin for admin routes and SecurityAPIKey for the
+	// routes requiring an API key, the other routes accept an optional one
+	Security string
+}
+
+// Route is a route of the server, Name is the full name of its handler.
+// Envelope routes wrap their responses in a utils.Envelope.
+type Route struct {
+	Method     string
+	Path       string
+	Name       string
+	Tag        string
+	Envelope   bool
+	Deprecated bool
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Document struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       Info                            `json:"info"`
+	Paths      map[string]map[string]*PathItem `json:"paths"`
+	Components Components                      `json:"components"`
+	Security   []map[string][]string           `json:"security,omitempty"`
+}
+
+type PathItem struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+var (
+	lock       sync.Mutex
+	operations = make(map[string]Operation)
+)
+
+// Register documents the routes of the handlers of ops
+func Register(ops ...Operation) {
+	lock.Lock()
+	defer lock.Unlock()
+	for _, op := range ops {
+		operations[utils.GetFunctionName(op.Handler)] = op
+	}
+}
+
+// Build documents routes with the registered operations. A route without an
+// operation is still listed, without its schemas.
+func Build(info Info, routes []Route) *Document {
+	lock.Lock()
+	defer lock.Unlock()
+
+	schemas := newSchemas()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]map[string]*PathItem),
+		Components: Components{
+			Schemas: schemas.components,
+			SecuritySchemes: map[string]*SecurityScheme{
+				SecurityAPIKey: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+				SecurityAdmin:  {Type: "http", Scheme: "bearer"},
+			},
+		},
+		// The API key is optional
+		Security: []map[string][]string{{}, {SecurityAPIKey: {}}},
+	}
+	errSchema := schemas.of(reflect.TypeOf(utils.ErrResponse{}))
+	validationSchema := schemas.of(reflect.TypeOf(utils.ValidationError{}))
+	errEnvelope := schemas.envelope(&Schema{Type: "null"})
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	for _, route := range routes {
+		op, ok := operations[route.Name]
+		if !ok {
+			op = Operation{Summary: route.Name[strings.LastIndex(route.Name, ".")+1:]}
+		}
+		path := openAPIPath(route.Path)
+
+		item := &PathItem{
+			OperationID: strings.ToLower(route.Method) + operationID(route.Path),
+			Summary:     op.Summary,
+			Description: op.Description,
+			Parameters:  schemas.params(op.Params),
+			Responses: map[string]*Response{
+				"default": {Description: "Error", Content: jsonContent(errSchema)},
+			},
+			Deprecated: route.Deprecated,
+		}
+		if route.Envelope {
+			item.Responses["default"].Content = jsonContent(errEnvelope)
+		}
+		if tag := withDefault(op.Tag, route.Tag); tag != "" {
+			item.Tags = []string{tag}
+		}
+		if len(item.Parameters) > 0 || op.Body != nil {
+			item.Responses["400"] = &Response{Description: "Invalid parameters", Content: jsonContent(validationSchema)}
+			if route.Envelope {
+				item.Responses["400"].Content = jsonContent(errEnvelope)
+			}
+		}
+		if op.Body != nil {
+			item.RequestBody = &RequestBody{Required: true, Content: jsonContent(schemas.value(op.Body))}
+		}
+		if op.Security != "" {
+			item.Security = []map[string][]string{{op.Security: {}}}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		res := &Response{Description: http.StatusText(status)}
+		switch {
+		case route.Envelope && op.Data != nil:
+			res.Content = jsonContent(schemas.envelope(schemas.value(op.Data)))
+		case route.Envelope && op.Response != nil:
+			res.Content = jsonContent(schemas.envelope(schemas.value(op.Response)))
+		case op.Response != nil:
+			res.Content = jsonContent(schemas.value(op.Response))
+		}
+		if op.ContentType != "" {
+			res.Content = map[string]*MediaType{op.ContentType: {}}
+		}
+		for name, description := range op.Headers {
+			if res.Headers == nil {
+				res.Headers = make(map[string]*Header)
+			}
+			res.Headers[name] = &Header{Description: description, Schema: &Schema{Type: "string"}}
+		}
+		item.Responses[strconv.Itoa(status)] = res
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*PathItem)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = item
+	}
+	return doc
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+// openAPIPath turns the :param segments of echo into {param}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operationID camel cases the segments of path, e.g. /api/stats/chart/txs
+// becomes ApiStatsChartTxs
+func operationID(path string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '_' || r == ':'
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func withDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
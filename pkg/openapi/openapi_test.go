@@ -0,0 +1,97 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+type testParams struct {
+	ID    string `param:"id"`
+	Chain string `query:"chain" validate:"omitempty,oneof=bitcoin evm"`
+	Size  int    `query:"size" validate:"omitempty,min=1,max=100"`
+}
+
+type testItem struct {
+	ID     string  `json:"id"`
+	Amount uint64  `json:"amount"`
+	Note   *string `json:"note,omitempty"`
+}
+
+func getTestItem() {}
+
+func listTestItems() {}
+
+func undocumented() {}
+
+func TestBuild(t *testing.T) {
+	Register(
+		Operation{Handler: getTestItem, Summary: "Get an item", Params: testParams{}, Response: testItem{}},
+		Operation{Handler: listTestItems, Summary: "List items", Response: []testItem{}, Security: SecurityAdmin},
+	)
+	routes := []Route{
+		{Method: http.MethodGet, Path: "/api/items/:id", Name: utils.GetFunctionName(getTestItem), Tag: "items", Deprecated: true},
+		{Method: http.MethodGet, Path: "/api/v1/items/:id", Name: utils.GetFunctionName(getTestItem), Tag: "items", Envelope: true},
+		{Method: http.MethodGet, Path: "/api/items", Name: utils.GetFunctionName(listTestItems)},
+		{Method: http.MethodGet, Path: "/ping", Name: utils.GetFunctionName(undocumented)},
+	}
+	doc := Build(Info{Title: "test", Version: "1"}, routes)
+
+	legacy := doc.Paths["/api/items/{id}"]["get"]
+	if legacy == nil {
+		t.Fatalf("paths = %v, want /api/items/{id}", doc.Paths)
+	}
+	if legacy.OperationID != "getApiItemsId" || !legacy.Deprecated || !reflect.DeepEqual(legacy.Tags, []string{"items"}) {
+		t.Errorf("legacy operation = %+v", legacy)
+	}
+
+	params := make(map[string]*Parameter)
+	for _, p := range legacy.Parameters {
+		params[p.Name] = p
+	}
+	if p := params["id"]; p == nil || p.In != "path" || !p.Required {
+		t.Errorf("id param = %+v, want a required path param", p)
+	}
+	if p := params["chain"]; p == nil || p.In != "query" || p.Required || !reflect.DeepEqual(p.Schema.Enum, []any{"bitcoin", "evm"}) {
+		t.Errorf("chain param = %+v, want an optional query enum", p)
+	}
+	if p := params["size"]; p == nil || p.Schema.Minimum == nil || *p.Schema.Minimum != 1 || p.Schema.Maximum == nil || *p.Schema.Maximum != 100 {
+		t.Errorf("size param = %+v, want bounds 1 and 100", p)
+	}
+	if legacy.Responses["400"] == nil {
+		t.Error("operation with params has no 400 response")
+	}
+	if got := legacy.Responses["200"].Content["application/json"].Schema.Ref; got != "#/components/schemas/testItem" {
+		t.Errorf("legacy response schema = %q, want the testItem component", got)
+	}
+	item := doc.Components.Schemas["testItem"]
+	if item == nil || !reflect.DeepEqual(item.Properties["note"].Type, []string{"string", "null"}) {
+		t.Errorf("testItem component = %+v, want a nullable note", item)
+	}
+
+	v1 := doc.Paths["/api/v1/items/{id}"]["get"]
+	if v1.Deprecated {
+		t.Error("versioned operation is deprecated")
+	}
+	envelope := v1.Responses["200"].Content["application/json"].Schema
+	if envelope.Properties["data"].Ref != "#/components/schemas/testItem" || !reflect.DeepEqual(envelope.Required, []string{"data", "meta", "errors"}) {
+		t.Errorf("versioned response = %+v, want an envelope of testItem", envelope)
+	}
+	if v1.Responses["default"].Content["application/json"].Schema.Properties == nil {
+		t.Error("versioned errors are not enveloped")
+	}
+
+	list := doc.Paths["/api/items"]["get"]
+	if !reflect.DeepEqual(list.Security, []map[string][]string{{SecurityAdmin: {}}}) {
+		t.Errorf("admin security = %v", list.Security)
+	}
+	if list.Responses["400"] != nil {
+		t.Error("operation without params has a 400 response")
+	}
+
+	if ping := doc.Paths["/ping"]["get"]; ping == nil || ping.Summary != "undocumented" || ping.Responses["200"].Content != nil {
+		t.Errorf("undocumented operation = %+v, want its handler name and no schema", ping)
+	}
+}
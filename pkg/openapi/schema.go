@@ -0,0 +1,242 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scalarorg/scalar-service/pkg/utils"
+)
+
+// Schema is a JSON schema, Type is a string or a list of types
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemas derives the schemas of types from their json tags. Named structs are
+// components, named after their type, prefixed by their package when two
+// packages have types of the same name.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// OneOf documents a response that is one of the types of its values
+type OneOf []any
+
+func (s *schemas) value(v any) *Schema {
+	if oneOf, ok := v.(OneOf); ok {
+		schema := &Schema{}
+		for _, v := range oneOf {
+			schema.OneOf = append(schema.OneOf, s.of(reflect.TypeOf(v)))
+		}
+		return schema
+	}
+	return s.of(reflect.TypeOf(v))
+}
+
+// envelope is the schema of a utils.Envelope of data
+func (s *schemas) envelope(data *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"data":   data,
+			"meta":   s.of(reflect.TypeOf(utils.Meta{})),
+			"errors": {Type: "array", Items: s.of(reflect.TypeOf(utils.ErrResponse{}))},
+		},
+		Required: []string{"data", "meta", "errors"},
+	}
+}
+
+func (s *schemas) of(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.of(t.Elem())
+		if typ, ok := schema.Type.(string); ok {
+			schema.Type = []string{typ, "null"}
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.of(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.of(t.Elem())}
+	case reflect.Struct:
+		return s.ref(t)
+	default:
+		// Interfaces may hold any value
+		return &Schema{}
+	}
+}
+
+// ref returns a reference to the component of a named struct, the schema of
+// anonymous and generic ones
+func (s *schemas) ref(t reflect.Type) *Schema {
+	if t.Name() == "" || strings.Contains(t.Name(), "[") {
+		return s.object(t)
+	}
+	if name, ok := s.names[t]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	name := t.Name()
+	if _, taken := s.components[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	s.names[t] = name
+	// Set before the properties so recursive types end
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(t, schema)
+	return schema
+}
+
+// fields adds the fields of t to schema, the ones of embedded structs
+// included. Fields without omitempty are required.
+func (s *schemas) fields(t reflect.Type, schema *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				s.fields(embedded, schema)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = s.of(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// params documents the fields of a struct with param or query tags, falling
+// back to their json tags, with the constraints of their validate tags
+func (s *schemas) params(v any) []*Parameter {
+	if v == nil {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			params = append(params, s.params(reflect.New(field.Type).Elem().Interface())...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		param := &Parameter{In: "query"}
+		if name := field.Tag.Get("param"); name != "" {
+			param.Name, param.In, param.Required = name, "path", true
+		} else if name := field.Tag.Get("query"); name != "" {
+			param.Name = name
+		} else {
+			param.Name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+		}
+		if param.Name == "" || param.Name == "-" {
+			continue
+		}
+
+		param.Schema = s.of(field.Type)
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			name, value, _ := strings.Cut(rule, "=")
+			switch name {
+			case "required":
+				param.Required = true
+			case "oneof":
+				for _, option := range strings.Fields(value) {
+					param.Schema.Enum = append(param.Schema.Enum, option)
+				}
+			case "min", "max":
+				if param.Schema.Type != "integer" && param.Schema.Type != "number" {
+					continue
+				}
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					if name == "min" {
+						param.Schema.Minimum = &n
+					} else {
+						param.Schema.Maximum = &n
+					}
+				}
+			case "timezone":
+				param.Schema.Description = "IANA time zone, e.g. Europe/Paris"
+			}
+		}
+		params = append(params, param)
+	}
+	return params
+}
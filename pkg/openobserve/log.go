@@ -2,6 +2,7 @@ package openobserve
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
@@ -10,7 +11,6 @@ import (
 	"time"
 
 	"github.com/rs/zerolog"
-	"github.com/rs/zerolog/log"
 	"github.com/scalarorg/scalar-service/pkg/utils"
 )
 
@@ -21,6 +21,7 @@ type LogWriter struct {
 	username string
 
 	ch      chan []byte
+	flush   chan chan struct{}
 	buf     [][]byte
 	buf_idx int
 }
@@ -34,22 +35,27 @@ func wrapData(b []byte) *bytes.Buffer {
 	return bytes.NewBuffer(data)
 }
 
-func NewLogWriter(level zerolog.Level) *LogWriter {
+// NewLogWriter creates a writer sending the logs of at least level to
+// OpenObserve. The credential must be the base64 of user:password.
+func NewLogWriter(level zerolog.Level) (*LogWriter, error) {
 	b, err := base64.StdEncoding.DecodeString(config.Credential)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Error decoding credential")
-		return nil
+		return nil, fmt.Errorf("invalid openobserve credential: %w", err)
+	}
+	username, password, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid openobserve credential: expected base64 of user:password")
 	}
-	rawUsernamePassword := strings.Split(string(b), ":")
 
 	lw := &LogWriter{
 		Level:    level,
-		username: rawUsernamePassword[0],
-		password: rawUsernamePassword[1],
+		username: username,
+		password: password,
 		ch:       make(chan []byte),
+		flush:    make(chan chan struct{}),
 	}
 	go lw.collectLogs()
-	return lw
+	return lw, nil
 }
 
 func (w *LogWriter) collectLogs() {
@@ -57,23 +63,47 @@ func (w *LogWriter) collectLogs() {
 	w.buf = make([][]byte, BATCH_SIZE)
 	w.buf_idx = 0
 
-	for logItem := range w.ch {
-		w.buf[w.buf_idx] = logItem
-		w.buf_idx++
-
-		// Send the logs if the buffer is full
-		if w.buf_idx == BATCH_SIZE {
-			w.send()
+	for {
+		select {
+		case logItem := <-w.ch:
+			w.buf[w.buf_idx] = logItem
+			w.buf_idx++
+
+			// Send the logs if the buffer is full
+			if w.buf_idx == BATCH_SIZE {
+				w.send()
+			}
+		case done := <-w.flush:
+			if w.buf_idx > 0 {
+				w.send()
+			}
+			close(done)
 		}
 	}
 }
 
+// Flush sends the buffered logs, waiting until they are sent or ctx is done
+func (w *LogWriter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case w.flush <- done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (w *LogWriter) send() {
 	defer func() {
 		w.buf_idx = 0
 	}()
 
-	b := bytes.Join(w.buf, []byte(","))
+	b := bytes.Join(w.buf[:w.buf_idx], []byte(","))
 
 	payload := wrapData(b)
 
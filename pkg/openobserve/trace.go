@@ -14,7 +14,6 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
 	oteltrace "go.opentelemetry.io/otel/trace"
@@ -46,15 +45,10 @@ func Trace(ctx context.Context, name string) (context.Context, *O2Span) {
 	return ctx, &O2Span{Span: span}
 }
 
-func SetupTraceHTTP() *sdktrace.TracerProvider {
-	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
-		propagation.TraceContext{},
-		propagation.Baggage{},
-	))
-
-	otlptracehttp.NewClient()
-
-	otlpHTTPExporter, err := otlptracehttp.New(context.TODO(),
+// NewTraceExporter creates an exporter sending the spans to the OTLP HTTP
+// endpoint of OpenObserve
+func NewTraceExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(ctx,
 		otlptracehttp.WithInsecure(),
 		otlptracehttp.WithEndpointURL(config.Endpoint),
 		otlptracehttp.WithURLPath(fmt.Sprintf("/api/%s/traces", config.OrgName)),
@@ -63,27 +57,6 @@ func SetupTraceHTTP() *sdktrace.TracerProvider {
 			"stream-name":   config.ServiceName,
 		}),
 	)
-
-	if err != nil {
-		fmt.Println("Error creating HTTP OTLP exporter: ", err)
-	}
-
-	res := resource.NewWithAttributes(
-		semconv.SchemaURL,
-		// the service name used to display traces in backends
-		semconv.ServiceNameKey.String(config.ServiceName),
-		semconv.ServiceVersionKey.String("0.0.1"),
-		attribute.String("environment", config.Env),
-	)
-
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-		sdktrace.WithResource(res),
-		sdktrace.WithBatcher(otlpHTTPExporter),
-	)
-	otel.SetTracerProvider(tp)
-
-	return tp
 }
 
 // Middleware returns echo middleware which will trace incoming requests.
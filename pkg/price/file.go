@@ -0,0 +1,42 @@
+package price
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FileProvider serves prices from a local price file, for offline use and tests
+type FileProvider struct {
+	history *History
+}
+
+var _ Provider = (*FileProvider)(nil)
+
+func NewFileProvider(path string) (*FileProvider, error) {
+	h, err := LoadHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileProvider{history: h}, nil
+}
+
+func (p *FileProvider) Price(_ context.Context, symbol string, at time.Time) (float64, error) {
+	price, ok := p.history.Lookup(symbol, at)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s on %s", ErrPriceNotFound, symbol, Day(at))
+	}
+	return price, nil
+}
+
+func (p *FileProvider) Prices(_ context.Context, symbol string, at []time.Time) (map[string]float64, error) {
+	prices := make(map[string]float64, len(at))
+	for _, t := range at {
+		price, ok := p.history.Lookup(symbol, t)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s on %s", ErrPriceNotFound, symbol, Day(t))
+		}
+		prices[Day(t)] = price
+	}
+	return prices, nil
+}
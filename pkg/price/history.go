@@ -0,0 +1,187 @@
+package price
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// maxLookback bounds how far back a missing day falls back to an earlier price
+const maxLookback = 7 * 24 * time.Hour
+
+type DailyPrice struct {
+	Date   string  `json:"date"`
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+}
+
+// History holds daily USD prices per symbol
+type History struct {
+	mu     sync.RWMutex
+	prices map[string]map[string]float64
+}
+
+func NewHistory() *History {
+	return &History{prices: make(map[string]map[string]float64)}
+}
+
+func Day(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+func (h *History) Set(symbol string, day string, price float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.prices[symbol] == nil {
+		h.prices[symbol] = make(map[string]float64)
+	}
+	h.prices[symbol][day] = price
+}
+
+// Exact returns the price recorded for the day of at
+func (h *History) Exact(symbol string, at time.Time) (float64, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	p, ok := h.prices[symbol][Day(at)]
+	return p, ok
+}
+
+// Lookup returns the price of the day of at, falling back to the closest
+// earlier day within maxLookback
+func (h *History) Lookup(symbol string, at time.Time) (float64, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	days := h.prices[symbol]
+	for d := time.Duration(0); d <= maxLookback; d += 24 * time.Hour {
+		if p, ok := days[Day(at.Add(-d))]; ok {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+func (h *History) All() []DailyPrice {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var all []DailyPrice
+	for symbol, days := range h.prices {
+		for day, p := range days {
+			all = append(all, DailyPrice{Date: day, Symbol: symbol, Price: p})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Symbol != all[j].Symbol {
+			return all[i].Symbol < all[j].Symbol
+		}
+		return all[i].Date < all[j].Date
+	})
+	return all
+}
+
+// LoadHistory reads a .csv (date,symbol,price) or .json ([]DailyPrice) file
+func LoadHistory(path string) (*History, error) {
+	h := NewHistory()
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var prices []DailyPrice
+	if isCSV(path) {
+		prices, err = readCSV(f)
+	} else {
+		err = json.NewDecoder(f).Decode(&prices)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, p := range prices {
+		if _, err := time.Parse(dateLayout, p.Date); err != nil {
+			return nil, fmt.Errorf("invalid date %q in %s", p.Date, path)
+		}
+		asset, err := Symbol(p.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%w in %s", err, path)
+		}
+		h.Set(asset, p.Date, p.Price)
+	}
+	return h, nil
+}
+
+// Save writes the history to path atomically, in the format of its extension
+func (h *History) Save(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".prices-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if isCSV(path) {
+		err = writeCSV(tmp, h.All())
+	} else {
+		enc := json.NewEncoder(tmp)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(h.All())
+	}
+	if err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func isCSV(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+func readCSV(r io.Reader) ([]DailyPrice, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var prices []DailyPrice
+	for i, record := range records {
+		if len(record) != 3 {
+			return nil, fmt.Errorf("line %d: expected date,symbol,price", i+1)
+		}
+		if i == 0 && record[0] == "date" {
+			continue
+		}
+		p, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price: %w", i+1, err)
+		}
+		prices = append(prices, DailyPrice{Date: strings.TrimSpace(record[0]), Symbol: record[1], Price: p})
+	}
+	return prices, nil
+}
+
+func writeCSV(w io.Writer, prices []DailyPrice) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "symbol", "price"}); err != nil {
+		return err
+	}
+	for _, p := range prices {
+		if err := cw.Write([]string{p.Date, p.Symbol, strconv.FormatFloat(p.Price, 'f', -1, 64)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
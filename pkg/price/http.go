@@ -0,0 +1,206 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// todayTTL is how long the price of the current, still moving day is reused
+const todayTTL = 10 * time.Minute
+
+// maxParallelFetches bounds the requests made to the oracle by one call
+const maxParallelFetches = 8
+
+type oracleResponse struct {
+	Price float64 `json:"price"`
+}
+
+// HTTPProvider fetches daily prices from an oracle. urlTemplate may contain
+// {symbol} and {date} (YYYY-MM-DD) and must answer {"price": <number>}.
+// Past days are immutable so they are stored in the local price file and
+// never fetched twice.
+type HTTPProvider struct {
+	urlTemplate string
+	path        string
+	client      *http.Client
+	history     *History
+
+	mu    sync.Mutex
+	today map[string]todayPrice
+	// saveMu serializes the writes of the price file
+	saveMu sync.Mutex
+}
+
+type todayPrice struct {
+	price     float64
+	fetchedAt time.Time
+}
+
+var _ Provider = (*HTTPProvider)(nil)
+
+func NewHTTPProvider(urlTemplate string, path string) (*HTTPProvider, error) {
+	if urlTemplate == "" {
+		return nil, errors.New("oracle url is required")
+	}
+
+	history := NewHistory()
+	if path != "" {
+		h, err := LoadHistory(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if h != nil {
+			history = h
+		}
+	}
+
+	return &HTTPProvider{
+		urlTemplate: urlTemplate,
+		path:        path,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		history:     history,
+		today:       make(map[string]todayPrice),
+	}, nil
+}
+
+func (p *HTTPProvider) Price(ctx context.Context, symbol string, at time.Time) (float64, error) {
+	prices, err := p.Prices(ctx, symbol, []time.Time{at})
+	if err != nil {
+		return 0, err
+	}
+	return prices[Day(at)], nil
+}
+
+// Prices fetches the days missing from the history in parallel and saves the
+// price file once, the days fetched before a failure are kept
+func (p *HTTPProvider) Prices(ctx context.Context, symbol string, at []time.Time) (map[string]float64, error) {
+	prices := make(map[string]float64, len(at))
+	today := Day(time.Now())
+	var missing []string
+	for _, t := range at {
+		day := Day(t)
+		if _, ok := prices[day]; ok || slices.Contains(missing, day) {
+			continue
+		}
+		if day == today {
+			price, err := p.todayPrice(ctx, symbol, day)
+			if err != nil {
+				return nil, err
+			}
+			prices[day] = price
+			continue
+		}
+		if price, ok := p.history.Exact(symbol, t); ok {
+			prices[day] = price
+			continue
+		}
+		missing = append(missing, day)
+	}
+	if len(missing) == 0 {
+		return prices, nil
+	}
+
+	fetched := make([]*float64, len(missing))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallelFetches)
+	for i, day := range missing {
+		g.Go(func() error {
+			price, err := p.fetch(gctx, symbol, day)
+			if err != nil {
+				return err
+			}
+			fetched[i] = &price
+			return nil
+		})
+	}
+	err := g.Wait()
+
+	var added int
+	for i, day := range missing {
+		if fetched[i] != nil {
+			p.history.Set(symbol, day, *fetched[i])
+			prices[day] = *fetched[i]
+			added++
+		}
+	}
+	if added > 0 {
+		p.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (p *HTTPProvider) save() {
+	if p.path == "" {
+		return
+	}
+	p.saveMu.Lock()
+	defer p.saveMu.Unlock()
+	if err := p.history.Save(p.path); err != nil {
+		log.Warn().Err(err).Str("path", p.path).Msg("failed to persist price history")
+	}
+}
+
+func (p *HTTPProvider) todayPrice(ctx context.Context, symbol string, day string) (float64, error) {
+	p.mu.Lock()
+	cached, ok := p.today[symbol]
+	p.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < todayTTL {
+		return cached.price, nil
+	}
+
+	price, err := p.fetch(ctx, symbol, day)
+	if err != nil {
+		return 0, err
+	}
+
+	p.mu.Lock()
+	p.today[symbol] = todayPrice{price: price, fetchedAt: time.Now()}
+	p.mu.Unlock()
+	return price, nil
+}
+
+func (p *HTTPProvider) fetch(ctx context.Context, symbol string, day string) (float64, error) {
+	u := strings.NewReplacer(
+		"{symbol}", url.QueryEscape(symbol),
+		"{date}", day,
+	).Replace(p.urlTemplate)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, fmt.Errorf("%w: %s on %s", ErrPriceNotFound, symbol, day)
+	}
+	if resp.StatusCode >= 400 {
+		return 0, fmt.Errorf("price oracle returned status %d", resp.StatusCode)
+	}
+
+	var body oracleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("invalid price oracle response: %w", err)
+	}
+	return body.Price, nil
+}
@@ -0,0 +1,105 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+)
+
+var (
+	ErrPriceNotFound = errors.New("price not found")
+	ErrUnknownSymbol = errors.New("unknown symbol")
+)
+
+// assets maps the symbols of the tokens bridged by Scalar to the asset they
+// are priced as, every token is BTC-backed so wrapped variants share its price
+var assets = map[string]string{
+	"BTC":  "BTC",
+	"SBTC": "BTC",
+	"TBTC": "BTC",
+	"WBTC": "BTC",
+}
+
+// Provider returns the USD price of one whole unit of an asset on a given day
+type Provider interface {
+	Price(ctx context.Context, symbol string, at time.Time) (float64, error)
+	// Prices returns the prices of the days of at keyed by Day, to value many
+	// amounts with one lookup
+	Prices(ctx context.Context, symbol string, at []time.Time) (map[string]float64, error)
+}
+
+var Default Provider
+
+func Init() {
+	switch config.Env.PRICE_PROVIDER {
+	case "file":
+		p, err := NewFileProvider(config.Env.PRICE_FILE)
+		if err != nil {
+			panic(fmt.Sprintf("failed to load price file: %+v", err))
+		}
+		Default = p
+	case "http":
+		p, err := NewHTTPProvider(config.Env.PRICE_ORACLE_URL, config.Env.PRICE_FILE)
+		if err != nil {
+			panic(fmt.Sprintf("failed to init price oracle: %+v", err))
+		}
+		Default = p
+	default:
+		return
+	}
+	log.Info().Str("provider", config.Env.PRICE_PROVIDER).Msg("Price provider initialized")
+}
+
+// Enabled reports whether USD valuation is available
+func Enabled() bool {
+	return Default != nil
+}
+
+// Symbol maps a token symbol to the asset it is priced as, empty and unknown
+// symbols fail rather than being priced as another asset
+func Symbol(symbol string) (string, error) {
+	asset, ok := assets[strings.ToUpper(strings.TrimSpace(symbol))]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownSymbol, symbol)
+	}
+	return asset, nil
+}
+
+// USD values amount raw token units with the given decimals at time at
+func USD(ctx context.Context, symbol string, amount uint64, decimals uint8, at time.Time) (float64, error) {
+	if Default == nil {
+		return 0, ErrPriceNotFound
+	}
+	asset, err := Symbol(symbol)
+	if err != nil {
+		return 0, err
+	}
+	p, err := Default.Price(ctx, asset, at)
+	if err != nil {
+		return 0, err
+	}
+	return Value(amount, decimals, p), nil
+}
+
+// DailyPrices returns the USD prices of symbol on the days of at, keyed by Day
+func DailyPrices(ctx context.Context, symbol string, at []time.Time) (map[string]float64, error) {
+	if Default == nil {
+		return nil, ErrPriceNotFound
+	}
+	asset, err := Symbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return Default.Prices(ctx, asset, at)
+}
+
+// Value values amount raw token units with the given decimals at price
+func Value(amount uint64, decimals uint8, price float64) float64 {
+	return float64(amount) / math.Pow10(int(decimals)) * price
+}
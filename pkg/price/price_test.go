@@ -0,0 +1,205 @@
+package price
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func date(day string) time.Time {
+	t, err := time.Parse(dateLayout, day)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []DailyPrice
+		wantErr bool
+	}{
+		{
+			name:    "csv with header",
+			file:    "prices.csv",
+			content: "date,symbol,price\n2025-01-02,sbtc, 95000.5\n2025-01-01,BTC,94000\n",
+			want: []DailyPrice{
+				{Date: "2025-01-01", Symbol: "BTC", Price: 94000},
+				{Date: "2025-01-02", Symbol: "BTC", Price: 95000.5},
+			},
+		},
+		{
+			name:    "json",
+			file:    "prices.json",
+			content: `[{"date":"2025-01-01","symbol":"wBTC","price":94000}]`,
+			want:    []DailyPrice{{Date: "2025-01-01", Symbol: "BTC", Price: 94000}},
+		},
+		{name: "unknown symbol", file: "prices.csv", content: "2025-01-01,ETH,3300\n", wantErr: true},
+		{name: "empty symbol", file: "prices.csv", content: "2025-01-01,,94000\n", wantErr: true},
+		{name: "invalid date", file: "prices.csv", content: "01/01/2025,BTC,1\n", wantErr: true},
+		{name: "invalid price", file: "prices.csv", content: "2025-01-01,BTC,abc\n", wantErr: true},
+		{name: "missing column", file: "prices.csv", content: "2025-01-01,BTC\n", wantErr: true},
+		{name: "invalid json", file: "prices.json", content: `{`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := LoadHistory(writeFile(t, tt.file, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadHistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(h.All(), tt.want) {
+				t.Errorf("LoadHistory() = %+v, want %+v", h.All(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	tests := []struct {
+		symbol  string
+		want    string
+		wantErr bool
+	}{
+		{symbol: "BTC", want: "BTC"},
+		{symbol: " sbtc ", want: "BTC"},
+		{symbol: "tBTC", want: "BTC"},
+		{symbol: "", wantErr: true},
+		{symbol: "FAKEBTC", wantErr: true},
+		{symbol: "ETH", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Symbol(tt.symbol)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Symbol(%q) error = %v, wantErr %v", tt.symbol, err, tt.wantErr)
+		}
+		if err != nil && !errors.Is(err, ErrUnknownSymbol) {
+			t.Errorf("Symbol(%q) error = %v, want ErrUnknownSymbol", tt.symbol, err)
+		}
+		if got != tt.want {
+			t.Errorf("Symbol(%q) = %q, want %q", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestHistoryLookup(t *testing.T) {
+	h := NewHistory()
+	h.Set("BTC", "2025-01-01", 100)
+
+	tests := []struct {
+		day    string
+		want   float64
+		wantOK bool
+	}{
+		{"2025-01-01", 100, true},
+		{"2025-01-08", 100, true},
+		{"2025-01-09", 0, false},
+		{"2024-12-31", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := h.Lookup("BTC", date(tt.day))
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Lookup(%s) = (%v, %v), want (%v, %v)", tt.day, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestHistorySave(t *testing.T) {
+	for _, name := range []string{"prices.csv", "prices.json"} {
+		t.Run(name, func(t *testing.T) {
+			h := NewHistory()
+			h.Set("BTC", "2025-01-01", 94000.25)
+			h.Set("BTC", "2025-01-02", 95000)
+
+			path := filepath.Join(t.TempDir(), name)
+			if err := h.Save(path); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := LoadHistory(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(loaded.All(), h.All()) {
+				t.Errorf("saved %+v, loaded %+v", h.All(), loaded.All())
+			}
+		})
+	}
+}
+
+func TestHTTPProviderPrices(t *testing.T) {
+	var requests atomic.Int32
+	oracle := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		day := r.URL.Query().Get("date")
+		if day == "2025-01-05" {
+			// Fail after the other days are fetched
+			time.Sleep(50 * time.Millisecond)
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"price": %s}`, strings.ReplaceAll(day[8:], "0", "1"))
+	}))
+	defer oracle.Close()
+
+	path := filepath.Join(t.TempDir(), "prices.csv")
+	p, err := NewHTTPProvider(oracle.URL+"?symbol={symbol}&date={date}", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	days := []time.Time{date("2025-01-01"), date("2025-01-02"), date("2025-01-01").Add(time.Hour)}
+	prices, err := p.Prices(context.Background(), "BTC", days)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]float64{"2025-01-01": 11, "2025-01-02": 12}; !reflect.DeepEqual(prices, want) {
+		t.Errorf("Prices() = %v, want %v", prices, want)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("oracle requests = %d, want 2", n)
+	}
+
+	// Fetched days are saved once and never fetched again
+	saved, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.All()) != 2 {
+		t.Errorf("saved %d prices, want 2", len(saved.All()))
+	}
+	if _, err := p.Price(context.Background(), "BTC", date("2025-01-02")); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("oracle requests after a cached lookup = %d, want 2", n)
+	}
+
+	// A failed day fails the call but keeps the days fetched with it
+	_, err = p.Prices(context.Background(), "BTC", []time.Time{date("2025-01-03"), date("2025-01-05")})
+	if !errors.Is(err, ErrPriceNotFound) {
+		t.Errorf("Prices() error = %v, want %v", err, ErrPriceNotFound)
+	}
+	if _, ok := p.history.Exact("BTC", date("2025-01-03")); !ok {
+		t.Error("price fetched alongside a failed day was dropped")
+	}
+}
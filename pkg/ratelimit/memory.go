@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultMemorySize bounds the buckets kept in memory, the least recently used
+// ones are dropped first and start full again
+const defaultMemorySize = 100_000
+
+type bucket struct {
+	key       string
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryStore keeps the buckets of a single instance in an LRU
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	buckets map[string]*list.Element
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore(size int) *MemoryStore {
+	if size <= 0 {
+		size = defaultMemorySize
+	}
+	return &MemoryStore{
+		size:    size,
+		ll:      list.New(),
+		buckets: make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, cost int, limit Limit) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var b *bucket
+	if el, ok := s.buckets[key]; ok {
+		b = el.Value.(*bucket)
+		b.tokens = refill(b.tokens, now.Sub(b.updatedAt), limit)
+		s.ll.MoveToFront(el)
+	} else {
+		b = &bucket{key: key, tokens: float64(limit.Burst)}
+		s.buckets[key] = s.ll.PushFront(b)
+		for s.ll.Len() > s.size {
+			s.remove(s.ll.Back())
+		}
+	}
+	b.updatedAt = now
+
+	allowed := b.tokens >= float64(cost)
+	if allowed {
+		b.tokens -= float64(cost)
+	}
+	return newResult(allowed, b.tokens, cost, limit), nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) remove(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.buckets, el.Value.(*bucket).key)
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/scalarorg/scalar-service/config"
+)
+
+// Limit is a token bucket holding up to Burst tokens, refilled with PerMinute
+// tokens every minute
+type Limit struct {
+	Burst     int
+	PerMinute int
+}
+
+// perSecond is the refill rate of the bucket
+func (l Limit) perSecond() float64 {
+	return float64(l.PerMinute) / 60
+}
+
+// Result is the state of a bucket after a request took tokens from it
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the denied request would be allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps the buckets. Take refills the bucket of key and takes cost
+// tokens from it when it holds enough, atomically.
+type Store interface {
+	Take(ctx context.Context, key string, cost int, limit Limit) (*Result, error)
+	Close() error
+}
+
+var Default Store
+
+func Init() {
+	switch config.Env.RATE_LIMIT_DRIVER {
+	case "redis":
+		s, err := NewRedisStore(config.Env.REDIS_URL)
+		if err != nil {
+			panic(fmt.Sprintf("failed to connect to redis: %+v", err))
+		}
+		Default = s
+	default:
+		Default = NewMemoryStore(defaultMemorySize)
+	}
+	log.Info().Str("driver", config.Env.RATE_LIMIT_DRIVER).Msg("Rate limiter initialized")
+}
+
+func Close() error {
+	if Default == nil {
+		return nil
+	}
+	return Default.Close()
+}
+
+// refill returns the tokens of a bucket that held tokens elapsed ago
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.perSecond())
+}
+
+// newResult describes a bucket left with tokens. A cost above the burst could
+// never be allowed, it is capped by the callers.
+func newResult(allowed bool, tokens float64, cost int, limit Limit) *Result {
+	rate := limit.perSecond()
+	res := &Result{
+		Allowed:   allowed,
+		Limit:     limit.Burst,
+		Remaining: int(math.Floor(tokens)),
+	}
+	if rate > 0 {
+		res.Reset = secondsDuration((float64(limit.Burst) - tokens) / rate)
+		if !allowed {
+			res.RetryAfter = secondsDuration((float64(cost) - tokens) / rate)
+		}
+	}
+	return res
+}
+
+func secondsDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s)) * time.Second
+}
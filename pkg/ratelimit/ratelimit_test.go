@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRefill(t *testing.T) {
+	limit := Limit{Burst: 10, PerMinute: 60}
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{"one token per second", 2, 3 * time.Second, 5},
+		{"partial token", 0, 500 * time.Millisecond, 0.5},
+		{"capped at the burst", 8, time.Minute, 10},
+		{"clock going back", 4, -time.Second, 4},
+	}
+	for _, tt := range tests {
+		if got := refill(tt.tokens, tt.elapsed, limit); got != tt.want {
+			t.Errorf("%s: refill() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	limit := Limit{Burst: 10, PerMinute: 60}
+	tests := []struct {
+		name    string
+		allowed bool
+		tokens  float64
+		cost    int
+		want    Result
+	}{
+		{
+			name: "allowed", allowed: true, tokens: 7.5, cost: 1,
+			want: Result{Allowed: true, Limit: 10, Remaining: 7, Reset: 3 * time.Second},
+		},
+		{
+			name: "denied", allowed: false, tokens: 1.2, cost: 5,
+			want: Result{Allowed: false, Limit: 10, Remaining: 1, Reset: 9 * time.Second, RetryAfter: 4 * time.Second},
+		},
+		{
+			name: "full", allowed: true, tokens: 10, cost: 1,
+			want: Result{Allowed: true, Limit: 10, Remaining: 10},
+		},
+	}
+	for _, tt := range tests {
+		if got := newResult(tt.allowed, tt.tokens, tt.cost, limit); *got != tt.want {
+			t.Errorf("%s: newResult() = %+v, want %+v", tt.name, *got, tt.want)
+		}
+	}
+
+	// Without refill the bucket never resets
+	if got := newResult(false, 0, 1, Limit{Burst: 10}); got.Reset != 0 || got.RetryAfter != 0 {
+		t.Errorf("newResult() without refill = %+v, want no reset", *got)
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(10)
+	limit := Limit{Burst: 5, PerMinute: 60}
+
+	for i, want := range []bool{true, true, false} {
+		res, err := s.Take(ctx, "a", 2, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Allowed != want {
+			t.Errorf("take %d allowed = %v, want %v", i, res.Allowed, want)
+		}
+	}
+
+	// A denied request takes nothing, one token is left
+	res, _ := s.Take(ctx, "a", 1, limit)
+	if !res.Allowed || res.Remaining != 0 {
+		t.Errorf("last token = %+v, want allowed with none remaining", *res)
+	}
+
+	// Other keys have their own bucket
+	if res, _ := s.Take(ctx, "b", 5, limit); !res.Allowed {
+		t.Error("bucket of another key is shared")
+	}
+
+	// The bucket refills with time
+	s.buckets["a"].Value.(*bucket).updatedAt = time.Now().Add(-3 * time.Second)
+	if res, _ := s.Take(ctx, "a", 3, limit); !res.Allowed {
+		t.Errorf("refilled bucket = %+v, want allowed", *res)
+	}
+}
+
+func TestMemoryStoreEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore(2)
+	limit := Limit{Burst: 1, PerMinute: 1}
+
+	for _, key := range []string{"a", "b", "a", "c"} {
+		s.Take(ctx, key, 1, limit)
+	}
+
+	// b was dropped and starts full again, a is still empty
+	if res, _ := s.Take(ctx, "a", 1, limit); res.Allowed {
+		t.Error("recently used bucket was dropped")
+	}
+	if res, _ := s.Take(ctx, "b", 1, limit); !res.Allowed {
+		t.Error("least recently used bucket was kept")
+	}
+	if n := len(s.buckets); n != 2 {
+		t.Errorf("kept %d buckets, want 2", n)
+	}
+}
+
+func ExampleMemoryStore_Take() {
+	s := NewMemoryStore(0)
+	res, _ := s.Take(context.Background(), "ip:192.0.2.1", 5, Limit{Burst: 60, PerMinute: 120})
+	fmt.Println(res.Allowed, res.Remaining, res.Reset)
+	// Output: true 55 3s
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "scalar-service:ratelimit:"
+
+// takeScript refills and takes from a bucket stored as a hash of its tokens
+// and update time in microseconds. The time of Redis is used so instances with
+// skewed clocks share buckets. A bucket expires once it would be full again.
+var takeScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(bucket[1]) or burst
+local updated_at = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - updated_at) / 1000000 * rate)
+
+local allowed = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', now)
+local ttl = 60000
+if rate > 0 then
+	ttl = math.ceil((burst - tokens) / rate * 1000) + 1000
+end
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore shares the buckets between instances of the service
+type RedisStore struct {
+	client *redis.Client
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(url string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+	return &RedisStore{client: client}, nil
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, cost int, limit Limit) (*Result, error) {
+	res, err := takeScript.Run(ctx, s.client, []string{redisKeyPrefix + key}, limit.Burst, limit.perSecond(), cost).Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 2 {
+		return nil, fmt.Errorf("unexpected rate limit script result %v", res)
+	}
+
+	allowed, _ := res[0].(int64)
+	rawTokens, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(rawTokens, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected rate limit tokens %q: %w", rawTokens, err)
+	}
+	return newResult(allowed == 1, tokens, cost, limit), nil
+}
+
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
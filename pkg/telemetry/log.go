@@ -0,0 +1,45 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/scalarorg/scalar-service/pkg/openobserve"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// openLogSinks opens the sinks of conf.LogSinks. The console is human
+// readable, the file gets the raw JSON lines and OpenObserve the info logs and
+// above.
+func (t *Telemetry) openLogSinks(conf Config) error {
+	for _, sink := range conf.LogSinks {
+		switch sink {
+		case LogSinkConsole:
+			t.logWriters = append(t.logWriters, zerolog.ConsoleWriter{Out: os.Stderr})
+		case LogSinkFile:
+			file := &lumberjack.Logger{
+				Filename:   conf.LogFile.Path,
+				MaxSize:    conf.LogFile.MaxSizeMB,
+				MaxBackups: conf.LogFile.MaxBackups,
+				MaxAge:     conf.LogFile.MaxAgeDays,
+				Compress:   true,
+			}
+			t.logWriters = append(t.logWriters, file)
+			t.shutdowns = append(t.shutdowns, func(context.Context) error {
+				return file.Close()
+			})
+		case LogSinkOpenObserve:
+			writer, err := openobserve.NewLogWriter(zerolog.InfoLevel)
+			if err != nil {
+				return err
+			}
+			t.logWriters = append(t.logWriters, writer)
+			t.shutdowns = append(t.shutdowns, writer.Flush)
+		default:
+			return fmt.Errorf("unknown log sink %q", sink)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime/debug"
+)
+
+const (
+	TraceExporterNone        = "none"
+	TraceExporterStdout      = "stdout"
+	TraceExporterOTLPHTTP    = "otlp-http"
+	TraceExporterOTLPGRPC    = "otlp-grpc"
+	TraceExporterOpenObserve = "openobserve"
+
+	LogSinkConsole     = "console"
+	LogSinkFile        = "file"
+	LogSinkOpenObserve = "openobserve"
+)
+
+type Config struct {
+	ServiceName string
+	Env         string
+
+	TraceExporter string
+	// OTLPEndpoint is the collector URL, an http:// scheme disables TLS
+	OTLPEndpoint string
+	OTLPHeaders  map[string]string
+	SampleRatio  float64
+
+	LogSinks []string
+	LogFile  LogFileConfig
+}
+
+// LogFileConfig rotates the JSON log file once it reaches MaxSizeMB, keeping
+// MaxBackups compressed files for MaxAgeDays. A zero keeps them all.
+type LogFileConfig struct {
+	Path       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+}
+
+// Telemetry owns the tracer provider and the log sinks, so they can be flushed
+// on shutdown
+type Telemetry struct {
+	shutdowns  []func(ctx context.Context) error
+	logWriters []io.Writer
+}
+
+// Setup installs the tracer provider of conf and opens its log sinks
+func Setup(ctx context.Context, conf Config) (*Telemetry, error) {
+	t := &Telemetry{}
+
+	tp, err := setupTracing(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	t.shutdowns = append(t.shutdowns, tp.Shutdown)
+
+	if err := t.openLogSinks(conf); err != nil {
+		return nil, errors.Join(err, t.Shutdown(ctx))
+	}
+	return t, nil
+}
+
+// LogWriters are the writers of the configured log sinks
+func (t *Telemetry) LogWriters() []io.Writer {
+	return t.logWriters
+}
+
+// Shutdown flushes the pending spans and logs, then closes the sinks
+func (t *Telemetry) Shutdown(ctx context.Context) error {
+	var err error
+	for _, shutdown := range t.shutdowns {
+		err = errors.Join(err, shutdown(ctx))
+	}
+	return err
+}
+
+// Version is the version of the running binary: its module version when it is
+// built from a tagged module, otherwise its VCS revision
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "(devel)"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scalarorg/scalar-service/pkg/openobserve"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func setupTracing(ctx context.Context, conf Config) (*sdktrace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		// the service name used to display traces in backends
+		semconv.ServiceNameKey.String(conf.ServiceName),
+		semconv.ServiceVersionKey.String(Version()),
+		attribute.String("environment", conf.Env),
+	)
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res)}
+
+	exporter, err := newSpanExporter(ctx, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", conf.TraceExporter, err)
+	}
+	if exporter == nil {
+		opts = append(opts, sdktrace.WithSampler(sdktrace.NeverSample()))
+	} else {
+		// Follow the sampling decision of the caller, if any
+		opts = append(opts,
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.SampleRatio))),
+			sdktrace.WithBatcher(exporter),
+		)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	return tp, nil
+}
+
+// newSpanExporter creates the exporter of conf.TraceExporter, nil when spans
+// are not exported
+func newSpanExporter(ctx context.Context, conf Config) (sdktrace.SpanExporter, error) {
+	switch conf.TraceExporter {
+	case TraceExporterOTLPHTTP:
+		return otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpointURL(conf.OTLPEndpoint),
+			otlptracehttp.WithHeaders(conf.OTLPHeaders),
+		)
+	case TraceExporterOTLPGRPC:
+		return otlptracegrpc.New(ctx,
+			otlptracegrpc.WithEndpointURL(conf.OTLPEndpoint),
+			otlptracegrpc.WithHeaders(conf.OTLPHeaders),
+		)
+	case TraceExporterStdout:
+		return stdouttrace.New()
+	case TraceExporterOpenObserve:
+		return openobserve.NewTraceExporter(ctx)
+	case TraceExporterNone, "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", conf.TraceExporter)
+	}
+}
@@ -7,17 +7,32 @@ type GeneralStats struct {
 }
 
 type AddressAmount struct {
+	Rank    int    `json:"rank,omitempty" gorm:"-"`
 	Address string `json:"address"`
 	Amount  uint64 `json:"amount"`
+
+	// Set on identity leaderboards: every address of the user, their volume of
+	// the ranked asset per chain and their volume of every asset
+	Addresses []string      `json:"addresses,omitempty" gorm:"-"`
+	Chains    []ChainAmount `json:"chains,omitempty" gorm:"-"`
+	Assets    []AssetAmount `json:"assets,omitempty" gorm:"-"`
 }
 
 type ChainAmount struct {
+	Rank   int    `json:"rank,omitempty" gorm:"-"`
 	Chain  string `json:"chain"`
 	Amount uint64 `json:"amount"`
 }
 
 type PathAmount struct {
+	Rank             int    `json:"rank,omitempty" gorm:"-"`
 	SourceChain      string `json:"source_chain"`
 	DestinationChain string `json:"destination_chain"`
 	Amount           uint64 `json:"amount"`
 }
+
+type AssetAmount struct {
+	Asset  string `json:"asset"`
+	Amount uint64 `json:"amount"`
+	Count  uint64 `json:"count"`
+}
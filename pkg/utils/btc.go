@@ -36,19 +36,27 @@ import (
 // 	return addressPubKey.AddressPubKeyHash().String(), nil
 // }
 
-func ScriptPubKeyToAddress(scriptHex string, network string) (btcutil.Address, error) {
-	params := &chaincfg.TestNet3Params
+// networkParams returns the params of a bitcoin chain id, bitcoin|1 is mainnet
+// and the other networks share the testnet address format
+func networkParams(network string) (*chaincfg.Params, error) {
 	parts := strings.Split(network, "|")
 	if len(parts) != 2 {
 		return nil, fmt.Errorf("invalid network format")
 	}
 	switch parts[1] {
-	case "0":
-		params = &chaincfg.MainNetParams
+	case "0", "1":
+		return &chaincfg.MainNetParams, nil
 	case "4":
-		params = &chaincfg.TestNet3Params
+		return &chaincfg.TestNet3Params, nil
 	default:
-		params = &chaincfg.TestNet3Params
+		return &chaincfg.TestNet3Params, nil
+	}
+}
+
+func ScriptPubKeyToAddress(scriptHex string, network string) (btcutil.Address, error) {
+	params, err := networkParams(network)
+	if err != nil {
+		return nil, err
 	}
 	// Decode the hex string into bytes
 	script, err := hex.DecodeString(scriptHex)
@@ -69,3 +77,24 @@ func ScriptPubKeyToAddress(scriptHex string, network string) (btcutil.Address, e
 	// TODO: Just support the simple case for now
 	return addresses[0], nil
 }
+
+// AddressToScriptPubKey is the inverse of ScriptPubKeyToAddress, it returns the
+// hex encoded locking script of a bitcoin address on network
+func AddressToScriptPubKey(address string, network string) (string, error) {
+	params, err := networkParams(network)
+	if err != nil {
+		return "", err
+	}
+	addr, err := btcutil.DecodeAddress(address, params)
+	if err != nil {
+		return "", err
+	}
+	if !addr.IsForNet(params) {
+		return "", fmt.Errorf("address is not for network %s", network)
+	}
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(script), nil
+}
@@ -0,0 +1,41 @@
+package utils
+
+import "testing"
+
+func TestAddressToScriptPubKey(t *testing.T) {
+	// BIP 173 test vectors
+	tests := []struct {
+		name    string
+		address string
+		network string
+		want    string
+		wantErr bool
+	}{
+		{name: "mainnet", address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", network: "bitcoin|1", want: "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{name: "testnet", address: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", network: "bitcoin|4", want: "0014751e76e8199196d454941c45d1b3a323f1433bd6"},
+		{name: "testnet on mainnet", address: "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", network: "bitcoin|1", wantErr: true},
+		{name: "mainnet on testnet", address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", network: "bitcoin|4", wantErr: true},
+		{name: "invalid network", address: "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", network: "bitcoin", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AddressToScriptPubKey(tt.address, tt.network)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddressToScriptPubKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("AddressToScriptPubKey() = %q, want %q", got, tt.want)
+			}
+			if tt.wantErr {
+				return
+			}
+			address, err := ScriptPubKeyToAddress(got, tt.network)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if address.EncodeAddress() != tt.address {
+				t.Errorf("ScriptPubKeyToAddress() = %q, want %q", address.EncodeAddress(), tt.address)
+			}
+		})
+	}
+}
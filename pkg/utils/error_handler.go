@@ -19,24 +19,32 @@ func HttpErrorHandler(err error, c echo.Context) {
 	}
 
 	if m, ok := err.(*ValidationError); ok {
-		c.JSON(http.StatusBadRequest, m)
+		Respond(c, http.StatusBadRequest, m, errorEnvelope(&ErrResponse{Message: m.Message, Metadata: m.Metadata}))
 	} else if m, ok := err.(*echo.HTTPError); ok {
 		switch mType := m.Message.(type) {
 		case string:
-			c.JSON(m.Code, ErrResponse{Message: mType})
+			errorJSON(c, m.Code, &ErrResponse{Message: mType})
 		case error:
-			c.JSON(m.Code, ErrResponse{Message: mType.Error()})
+			errorJSON(c, m.Code, &ErrResponse{Message: mType.Error()})
 		}
 	} else {
 		log.Error().Str("err", err.Error()).Msg("http error")
 		if err == pgx.ErrNoRows || err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, ErrResponse{
+			errorJSON(c, http.StatusNotFound, &ErrResponse{
 				Message: "Resource not found",
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, ErrResponse{
+		errorJSON(c, http.StatusInternalServerError, &ErrResponse{
 			Message: http.StatusText(http.StatusInternalServerError),
 		})
 	}
 }
+
+func errorJSON(c echo.Context, code int, res *ErrResponse) {
+	Respond(c, code, res, errorEnvelope(res))
+}
+
+func errorEnvelope(errs ...*ErrResponse) *Envelope {
+	return &Envelope{Errors: errs}
+}
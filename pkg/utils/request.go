@@ -7,6 +7,10 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// APIKeyContextKey holds the prefix of the API key of the request, logged with
+// the request
+const APIKeyContextKey = "api_key_prefix"
+
 func RequestLogMiddleware() echo.MiddlewareFunc {
 	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogURI:     true,
@@ -29,8 +33,11 @@ func RequestLogMiddleware() echo.MiddlewareFunc {
 			e.Int("status", v.Status).
 				Str("method", v.Method).
 				Str("uri", v.URI).
-				Str("latency", v.Latency.String()).
-				Send()
+				Str("latency", v.Latency.String())
+			if prefix, ok := c.Get(APIKeyContextKey).(string); ok {
+				e.Str("api_key", prefix)
+			}
+			e.Send()
 			return nil
 		},
 	})
@@ -1,10 +1,85 @@
 package utils
 
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// APIV1Prefix prefixes the routes of the versioned API, which answer with an
+// Envelope. The routes under /api alone are the frozen legacy API.
+const APIV1Prefix = "/api/v1"
+
 func NewListResult[T any](data T, total int) *ListPublicResponse[T] {
 	return &ListPublicResponse[T]{Data: data, Total: total}
 }
 
+// ListPublicResponse is the list response of the legacy API
 type ListPublicResponse[T any] struct {
 	Data  T   `json:"data"`
 	Total int `json:"total,omitempty"`
 }
+
+// Envelope is the response of every route of the versioned API, data is null
+// when the request failed and errors holds why
+type Envelope struct {
+	Data   any            `json:"data"`
+	Meta   Meta           `json:"meta"`
+	Errors []*ErrResponse `json:"errors"`
+}
+
+// Meta describes the page of a list, it is empty for other responses
+type Meta struct {
+	Total      *int   `json:"total,omitempty"`
+	Page       *int   `json:"page,omitempty"`
+	Size       *int   `json:"size,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// IsV1 reports whether the request is made to the versioned API
+func IsV1(c echo.Context) bool {
+	path := c.Request().URL.Path
+	return path == APIV1Prefix || strings.HasPrefix(path, APIV1Prefix+"/")
+}
+
+// UnversionedPath returns the legacy path of a route of the versioned API, so
+// both versions share their cache entries, rate limits and CORS policies
+func UnversionedPath(path string) string {
+	if path == APIV1Prefix || strings.HasPrefix(path, APIV1Prefix+"/") {
+		return "/api" + strings.TrimPrefix(path, APIV1Prefix)
+	}
+	return path
+}
+
+// JSON sends data as is on the legacy API and in an Envelope on the versioned
+// one
+func JSON(c echo.Context, status int, data any) error {
+	return Respond(c, status, data, &Envelope{Data: data})
+}
+
+// ListJSON sends a page of a list of total items
+func ListJSON[T any](c echo.Context, data T, total, page, size int) error {
+	return Respond(c, http.StatusOK, NewListResult(data, total), &Envelope{
+		Data: data,
+		Meta: Meta{Total: &total, Page: &page, Size: &size},
+	})
+}
+
+// Respond sends legacy on the legacy API and envelope on the versioned one
+func Respond(c echo.Context, status int, legacy any, envelope *Envelope) error {
+	if !IsV1(c) {
+		return c.JSON(status, legacy)
+	}
+	if envelope.Errors == nil {
+		envelope.Errors = []*ErrResponse{}
+	}
+	return c.JSON(status, envelope)
+}
+
+// CacheImmutable lets browsers and shared caches reuse a response which never
+// changes for maxAge seconds without revalidating it
+func CacheImmutable(c echo.Context, maxAge int) {
+	c.Response().Header().Set(echo.HeaderCacheControl, fmt.Sprintf("public, max-age=%d, immutable", maxAge))
+}